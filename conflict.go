@@ -0,0 +1,152 @@
+package qb
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// ConflictResolution is the algorithm used to resolve constraint violations
+// of an INSERT or UPDATE query, as in INSERT OR REPLACE
+type ConflictResolution string
+
+// The conflict resolution algorithms supported by SQLite
+const (
+	ConflictRollback ConflictResolution = "ROLLBACK"
+	ConflictAbort    ConflictResolution = "ABORT"
+	ConflictFail     ConflictResolution = "FAIL"
+	ConflictIgnore   ConflictResolution = "IGNORE"
+	ConflictReplace  ConflictResolution = "REPLACE"
+)
+
+func (r ConflictResolution) write(buf *bytes.Buffer, verb string) {
+	buf.WriteString(verb)
+	if r != "" {
+		buf.WriteString(" OR ")
+		buf.WriteString(string(r))
+	}
+}
+
+// ConflictClause represents an ON CONFLICT clause of an INSERT query
+type ConflictClause struct {
+	target      []string
+	targetWhere whereClause
+	doNothing   bool
+	sets        []setClause
+	excluded    bool
+	updateWhere whereClause
+}
+
+// Conflict creates an ON CONFLICT clause for the given conflict target
+// columns. Without any Set calls it renders DO NOTHING, which is the only
+// action allowed without a conflict target.
+func Conflict(target ...string) *ConflictClause {
+	return &ConflictClause{target: target}
+}
+
+// Where adds a condition to the conflict target to match a partial unique
+// index, which requires a conflict target
+func (c *ConflictClause) Where(condition interface{}, params ...interface{}) *ConflictClause {
+	c.targetWhere.addWhere(condition, params...)
+	return c
+}
+
+// DoNothing makes the clause render DO NOTHING, discarding any assignments
+func (c *ConflictClause) DoNothing() *ConflictClause {
+	c.doNothing = true
+	return c
+}
+
+// Set adds a column = value assignment to the DO UPDATE SET clause. A Builder
+// value is rendered as an expression or subquery.
+func (c *ConflictClause) Set(column string, value interface{}) *ConflictClause {
+	c.sets = append(c.sets, setValue(column, value))
+	return c
+}
+
+// SetExpr adds a column = expression assignment to the DO UPDATE SET clause
+func (c *ConflictClause) SetExpr(column string, expr string, params ...interface{}) *ConflictClause {
+	c.sets = append(c.sets, setClause{column: column, value: Expr(expr, params...)})
+	return c
+}
+
+// SetExcluded adds a column = excluded.column assignment for every given
+// column. Without columns it does so for every inserted column that is not
+// part of the conflict target or tagged as pk in the inserted record.
+func (c *ConflictClause) SetExcluded(columns ...string) *ConflictClause {
+	if len(columns) == 0 {
+		c.excluded = true
+	}
+	for _, column := range columns {
+		c.sets = append(c.sets, setClause{column: column, value: Expr("excluded." + column)})
+	}
+	return c
+}
+
+// UpdateWhere adds a condition to the DO UPDATE clause that limits which
+// conflicting rows are updated
+func (c *ConflictClause) UpdateWhere(condition interface{}, params ...interface{}) *ConflictClause {
+	c.updateWhere.addWhere(condition, params...)
+	return c
+}
+
+func (c *ConflictClause) build(buf *bytes.Buffer, columns []string, keys []string) error {
+	if len(c.target) == 0 && len(c.targetWhere.wheres) > 0 {
+		return &BuildError{Reason: "WHERE requires a conflict target"}
+	}
+
+	buf.WriteString(" ON CONFLICT")
+	if len(c.target) > 0 {
+		buf.WriteString(" (")
+		buf.WriteString(strings.Join(c.target, ", "))
+		buf.WriteString(")")
+		if err := c.targetWhere.writeWhere(buf); err != nil {
+			return err
+		}
+	}
+
+	sets := c.assignments(columns, keys)
+	if c.doNothing || len(sets) == 0 {
+		buf.WriteString(" DO NOTHING")
+		return nil
+	}
+
+	if len(c.target) == 0 {
+		return &BuildError{Reason: "DO UPDATE requires a conflict target"}
+	}
+
+	buf.WriteString(" DO UPDATE SET ")
+	if err := writeSets(buf, sets); err != nil {
+		return err
+	}
+
+	return c.updateWhere.writeWhere(buf)
+}
+
+func (c *ConflictClause) params(columns []string, keys []string) []interface{} {
+	var p []interface{}
+	if len(c.target) > 0 {
+		p = append(p, c.targetWhere.whereParams()...)
+	}
+	if sets := c.assignments(columns, keys); !c.doNothing && len(sets) > 0 {
+		p = append(p, setParams(sets)...)
+		p = append(p, c.updateWhere.whereParams()...)
+	}
+	return p
+}
+
+// assignments returns the explicit assignments followed by the excluded.*
+// assignments for every inserted column not in the target or keys
+func (c *ConflictClause) assignments(columns []string, keys []string) []setClause {
+	if !c.excluded {
+		return c.sets
+	}
+	sets := append([]setClause{}, c.sets...)
+	for _, column := range columns {
+		if slices.Contains(c.target, column) || slices.Contains(keys, column) {
+			continue
+		}
+		sets = append(sets, setClause{column: column, value: Expr("excluded." + column)})
+	}
+	return sets
+}
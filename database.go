@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
-	"errors"
 
 	// We assume sqlite
 	_ "modernc.org/sqlite"
@@ -49,7 +48,24 @@ func Open(ctx context.Context, conn string) (*DB, error) {
 // BeginTx starts a transaction. The default isolation level is dependent on the driver.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	tx, err := db.DB.BeginTx(ctx, opts)
-	return &Tx{tx}, err
+	t := &Tx{tx}
+	if err == nil {
+		t.SetStrict(db.strictMode())
+	}
+	return t, err
+}
+
+// SetStrict sets the default StrictMode for all queries loaded through the DB,
+// combined with the mode in the context and the mode of the query. It is
+// inherited by transactions started afterwards.
+func (db *DB) SetStrict(mode StrictMode) {
+	setStrictDefault(db, mode)
+}
+
+// Close closes the database
+func (db *DB) Close() error {
+	setStrictDefault(db, 0)
+	return db.DB.Close()
 }
 
 // Select creates and returns a new SelectQuery
@@ -64,6 +80,10 @@ func (db *DB) Update() *UpdateQuery { return &UpdateQuery{} }
 // Delete creates and returns a new DeleteQuery
 func (db *DB) Delete() *DeleteQuery { return &DeleteQuery{} }
 
+func (db *DB) strictMode() StrictMode {
+	return strictDefault(db)
+}
+
 func (db *DB) runnerFor(ctx context.Context) runner {
 	if tx := GetTxCtx(ctx); tx != nil {
 		return tx.Tx
@@ -76,9 +96,23 @@ func (db *DB) Exec(ctx context.Context, b Builder) (sql.Result, error) {
 	return exec(ctx, db.runnerFor(ctx), b)
 }
 
-// Load executes a read query and scans the results into dest
+// ExecReturning executes a write query with a RETURNING clause, using the
+// transaction in ctx if present, and scans the returned rows into dest. Use
+// it to write generated columns back into the record passed to Record.
+func (db *DB) ExecReturning(ctx context.Context, b Builder, dest interface{}) (int, error) {
+	return execReturning(withStrictDefault(ctx, db.strictMode()), db.runnerFor(ctx), b, dest)
+}
+
+// Load executes a read query and scans the results into dest. Besides
+// structs and scalars, rows are loaded into a map[string]interface{} or an
+// []interface{} per row, or into a map keyed by the first column
 func (db *DB) Load(ctx context.Context, b Builder, dest interface{}) (int, error) {
-	return query(ctx, db.runnerFor(ctx), b, dest)
+	return query(withStrictDefault(ctx, db.strictMode()), db.runnerFor(ctx), b, dest)
+}
+
+// Rows executes a read query and returns the raw rows, which must be closed
+func (db *DB) Rows(ctx context.Context, b Builder) (*sql.Rows, error) {
+	return queryRows(ctx, db.runnerFor(ctx), b)
 }
 
 // LoadValue executes a read query and scans the scalar result into dest
@@ -88,7 +122,7 @@ func (db *DB) LoadValue(ctx context.Context, b Builder, dest interface{}) error
 		return err
 	}
 	if rows == 0 {
-		return errors.New("no records returned")
+		return ErrNoRows
 	}
 	return nil
 }
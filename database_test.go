@@ -2,6 +2,9 @@ package qb
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -97,6 +100,198 @@ func TestInsertIntoDatabase(t *testing.T) {
 	}
 }
 
+func TestInsertRecordIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, "")
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	n := struct {
+		ID      int64  `db:"id,pk,autoincrement"`
+		Name    string `db:"name"`
+		Content string `db:"content,omitempty"`
+	}{
+		Name: "Test Name",
+	}
+
+	result, err := db.Exec(ctx, db.Insert().InTo("notes").Record(&n))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n.ID, _ = result.LastInsertId()
+	n.Content = "Test Content"
+
+	if _, err := db.Exec(ctx, db.Update().Table("notes").SetRecord(&n).Where("id = ?", n.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := n
+	loaded.Name, loaded.Content = "", ""
+	if _, err := db.Load(ctx, db.Select().From("notes").Where("id = ?", n.ID), &loaded); err != nil {
+		t.Fatal(err)
+	} else if loaded.Name != "Test Name" || loaded.Content != "Test Content" {
+		t.Fatalf("Expected the record to be stored but got %v", loaded)
+	}
+}
+
+func TestExecReturningIntoDatabase(t *testing.T) {
+	db := createTestDB(t, `CREATE TABLE notes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	created_at TEXT NOT NULL DEFAULT 'now'
+);`, "")
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	type record struct {
+		ID        int64  `db:"id,pk,autoincrement"`
+		Name      string `db:"name"`
+		CreatedAt string `db:"created_at,readonly"`
+	}
+
+	n := record{Name: "Test Name"}
+
+	if _, err := db.ExecReturning(ctx, db.Insert().InTo("notes").Record(&n), &n); err != ErrNoReturning {
+		t.Fatalf("Expected ErrNoReturning but got %v", err)
+	}
+
+	q := db.Insert().InTo("notes").Record(&n).Returning("id", "created_at")
+	if rows, err := db.ExecReturning(ctx, q, &n); err != nil {
+		t.Fatal(err)
+	} else if rows != 1 {
+		t.Fatalf("Expected 1 row but got %d", rows)
+	} else if n.ID != 1 || n.CreatedAt != "now" {
+		t.Fatalf("Expected the returned columns to be scanned but got %v", n)
+	}
+
+	n.Name = "Updated Name"
+	u := db.Update().Table("notes").SetRecord(&n).Where("id = ?", n.ID).Returning("name")
+	n.Name = ""
+	if _, err := db.ExecReturning(ctx, u, &n); err != nil {
+		t.Fatal(err)
+	} else if n.Name != "Updated Name" {
+		t.Fatalf("Expected Updated Name but got %s", n.Name)
+	}
+
+	defer func(limit int) { maxVariables = limit }(maxVariables)
+	maxVariables = 1
+
+	records := []record{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	returned := []record{}
+	if rows, err := db.ExecReturning(ctx, db.Insert().InTo("notes").Records(records).Returning("*"), &returned); err != nil {
+		t.Fatal(err)
+	} else if rows != 3 || len(returned) != 3 {
+		t.Fatalf("Expected 3 rows but got %d", rows)
+	} else if returned[2].ID != 4 || returned[2].Name != "c" {
+		t.Fatalf("Expected the last record to be returned but got %v", returned[2])
+	}
+}
+
+func TestUpsertIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES (1, "Fuu", "This is bar");`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	n := note{ID: 2, Name: "Fuu", Content: "This is new"}
+
+	q := db.Insert().InTo("notes").Columns("id", "name", "content").Record(&n)
+	q.Upsert(Conflict("name").SetExcluded("content").UpdateWhere("content != ?", "This is new"))
+	if result, err := db.Exec(ctx, q); err != nil {
+		t.Fatal(err)
+	} else if affected, _ := result.RowsAffected(); affected != 1 {
+		t.Fatalf("Expected 1 row affected but got %d", affected)
+	}
+
+	loaded := note{}
+	if _, err := db.Load(ctx, db.Select().From("notes"), &loaded); err != nil {
+		t.Fatal(err)
+	} else if loaded.ID != 1 || loaded.Content != "This is new" {
+		t.Fatalf("Expected the existing note to be updated but got %v", loaded)
+	}
+
+	q = db.Insert().InTo("notes").Columns("id", "name", "content").Record(&n)
+	q.Upsert(Conflict("name").DoNothing())
+	if result, err := db.Exec(ctx, q); err != nil {
+		t.Fatal(err)
+	} else if affected, _ := result.RowsAffected(); affected != 0 {
+		t.Fatalf("Expected 0 rows affected but got %d", affected)
+	}
+}
+
+func TestInsertFromSelectIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	source := db.Select().From("notes").Columns("name || ' copy'", "content").Where("id = ?", 1)
+	if result, err := db.Exec(ctx, db.Insert().InTo("notes").Columns("name", "content").FromSelect(source)); err != nil {
+		t.Fatal(err)
+	} else if id, _ := result.LastInsertId(); id != 3 {
+		t.Fatalf("Expected note.ID to be 3 but got %d", id)
+	}
+
+	if _, err := db.Exec(ctx, db.Insert().InTo("notes").DefaultValues()); err == nil {
+		t.Fatal("Expected not null constraint to kick in but it did not")
+	}
+
+	copies := db.Select().From("notes").Columns("name || ' copy'").Where("id = ?", 2).
+		Union(db.Select().From("notes").Columns("name"))
+	q := db.Insert().InTo("notes").Columns("name").FromSelect(copies).Upsert(Conflict("name").DoNothing())
+	if result, err := db.Exec(ctx, q); err != nil {
+		t.Fatal(err)
+	} else if affected, _ := result.RowsAffected(); affected != 1 {
+		t.Fatalf("Expected 1 note to be inserted but got %d", affected)
+	}
+}
+
+func TestBulkInsertIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, "")
+	defer db.Close()
+
+	defer func(limit int) { maxVariables = limit }(maxVariables)
+	maxVariables = 4
+
+	ctx := context.TODO()
+
+	notes := []note{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		notes = append(notes, note{Name: name, Content: "Test Content"})
+	}
+
+	result, err := db.Exec(ctx, db.Insert().InTo("notes").Columns("name", "content").Records(notes))
+	if err != nil {
+		t.Fatal(err)
+	} else if affected, _ := result.RowsAffected(); affected != 5 {
+		t.Fatalf("Expected 5 rows affected but got %d", affected)
+	}
+
+	totalCount := 0
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(id)"), &totalCount); err != nil {
+		t.Fatal(err)
+	} else if totalCount != 5 {
+		t.Fatalf("Expected 5 records but got %d", totalCount)
+	}
+
+	// A failing batch rolls back the batches before it
+	q := db.Insert().InTo("notes").Columns("name", "content")
+	q.Values("f", "Test Content").Values("g", "Test Content").Values("a", "Duplicate")
+	if _, err := db.Exec(ctx, q); err == nil {
+		t.Fatal("Expected unique constraint to kick in but it did not")
+	}
+
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(id)"), &totalCount); err != nil {
+		t.Fatal(err)
+	} else if totalCount != 5 {
+		t.Fatalf("Expected 5 records but got %d", totalCount)
+	}
+}
+
 func TestUpdateIntoDatabase(t *testing.T) {
 	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES (1, "Fuu", "This is bar");`)
 	defer db.Close()
@@ -125,6 +320,25 @@ func TestUpdateIntoDatabase(t *testing.T) {
 	}
 }
 
+func TestUpdateExpressionIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES (1, "Fuu", "This is bar");`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	q := db.Update().Table("notes").SetExpr("content", "content || ?", "!").Set("name", Expr("UPPER(name)")).Where("id = ?", 1)
+	if _, err := db.Exec(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+
+	n := note{}
+	if _, err := db.Load(ctx, db.Select().From("notes"), &n); err != nil {
+		t.Fatal(err)
+	} else if n.Name != "FUU" || n.Content != "This is bar!" {
+		t.Fatalf("Expected the expressions to be evaluated but got %v", n)
+	}
+}
+
 func TestDeleteIntoDatabase(t *testing.T) {
 	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES (1, "Fuu", "This is bar");`)
 	defer db.Close()
@@ -148,3 +362,400 @@ func TestDeleteIntoDatabase(t *testing.T) {
 		t.Fatalf("Expected 0 record but got %d", totalCount)
 	}
 }
+
+func TestSelectCompoundFromDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu"),
+	(3, "Bar", "This is test")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	q := db.Select().From("notes").Where("id = ?", 1)
+	q.Union(db.Select().From("notes").Where("id = ?", 3), db.Select().From("notes").Where("id = ?", 1))
+	q.OrderBy("id", "DESC")
+
+	notes := []note{}
+	if _, err := db.Load(ctx, q, &notes); err != nil {
+		t.Fatal(err)
+	} else if len(notes) != 2 || notes[0].ID != 3 || notes[1].ID != 1 {
+		t.Fatalf("Expected notes 3 and 1 but got %v", notes)
+	}
+}
+
+func TestSelectRecursiveFromDatabase(t *testing.T) {
+	db := createTestDB(t, `CREATE TABLE categories (id INTEGER PRIMARY KEY, parent_id INTEGER NULL);`, `INSERT INTO categories (id, parent_id) VALUES
+	(1, NULL), (2, 1), (3, 2), (4, NULL), (5, 3)`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	root := db.Select().From("categories").Columns("id").Where("id = ?", 2)
+	children := db.Select().From("categories c").Columns("c.id").Join("JOIN tree ON c.parent_id = tree.id")
+	q := db.Select().From("tree").Columns("id").WithRecursive("tree", []string{"id"}, root.UnionAll(children)).OrderBy("id", "ASC")
+
+	ids := []int64{}
+	if _, err := db.Load(ctx, q, &ids); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(ids, []int64{2, 3, 5}) {
+		t.Fatalf("Expected [2 3 5] but got %v", ids)
+	}
+}
+
+func TestSelectWindowFromDatabase(t *testing.T) {
+	db := createTestDB(t, `CREATE TABLE sales (day INTEGER, region TEXT, amount INTEGER);`, `INSERT INTO sales (day, region, amount) VALUES
+	(1, "north", 10), (2, "north", 20), (3, "north", 30), (1, "south", 5), (2, "south", 5)`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	type row struct {
+		Day          int
+		Region       string
+		RunningTotal int
+	}
+
+	q := db.Select().From("sales").
+		Columns("day", "region").ColumnExprs(As(WindowFn("SUM(amount)").Over("w"), "running_total")).
+		Window("w", Window().PartitionBy("region").OrderBy("day", "ASC")).
+		OrderBy("region", "ASC").OrderBy("day", "ASC")
+
+	rows := []row{}
+	if _, err := db.Load(ctx, q, &rows); err != nil {
+		t.Fatal(err)
+	} else if len(rows) != 5 || rows[2].RunningTotal != 60 || rows[4].RunningTotal != 10 {
+		t.Fatalf("Expected running totals per region but got %v", rows)
+	}
+}
+
+func TestSelectSliceParamFromDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu"),
+	(3, "Bar", "This is test")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	notes := []note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Where("id IN (?)", []int64{1, 3}), &notes); err != nil {
+		t.Fatal(err)
+	} else if len(notes) != 2 {
+		t.Fatalf("Expected 2 rows but got %d", len(notes))
+	}
+
+	notes = []note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Where("id IN (?)", []int64{}), &notes); err != nil {
+		t.Fatal(err)
+	} else if len(notes) != 0 {
+		t.Fatalf("Expected 0 rows but got %d", len(notes))
+	}
+}
+
+func TestDeleteWithLimitFromDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu"),
+	(3, "Bar", "This is test")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	deleted := []note{}
+	q := db.Delete().From("notes").Where("id > ?", 1).OrderBy("id", "DESC").Limit(1).Returning("id", "name")
+	if rows, err := db.ExecReturning(ctx, q, &deleted); err != nil {
+		t.Fatal(err)
+	} else if rows != 1 || deleted[0].ID != 3 {
+		t.Fatalf("Expected note 3 to be deleted but got %v", deleted)
+	}
+
+	if _, err := db.Exec(ctx, db.Update().Table("notes").Set("content", "first").OrderBy("id", "ASC").Limit(1)); err != nil {
+		t.Fatal(err)
+	}
+
+	n := note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Where("content = ?", "first"), &n); err != nil {
+		t.Fatal(err)
+	} else if n.ID != 1 {
+		t.Fatalf("Expected note 1 to be updated but got %v", n)
+	}
+}
+
+func TestStrictLoadFromDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	notes := []note{}
+	q := db.Select().From("notes").Columns("id", "name AS title")
+	if _, err := db.Load(ctx, q, &notes); err != nil {
+		t.Fatalf("Expected unmapped columns to be ignored but got %s", err)
+	}
+
+	var mappingErr *MappingError
+	if _, err := db.Load(WithStrict(ctx, StrictColumns), q, &notes); !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected a MappingError but got %v", err)
+	} else if !reflect.DeepEqual(mappingErr.Columns, []string{"title"}) {
+		t.Fatalf("Expected column title to be reported but got %v", mappingErr.Columns)
+	}
+
+	if _, err := db.Load(ctx, q.Strict(StrictColumns), &notes); err == nil {
+		t.Fatal("Expected an error for a strict query")
+	}
+
+	type requiredNote struct {
+		ID      int64
+		Name    string `db:"name,required"`
+		Content string
+	}
+
+	db.SetStrict(StrictColumns | StrictFields)
+	if _, err := All[requiredNote](ctx, db, db.Select().From("notes").Columns("id", "content")); !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected a MappingError but got %v", err)
+	} else if !reflect.DeepEqual(mappingErr.Fields, []string{"name"}) {
+		t.Fatalf("Expected field name to be reported but got %v", mappingErr.Fields)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := All[requiredNote](ctx, tx, tx.Select().From("notes").Columns("id", "content")); !errors.As(err, &mappingErr) {
+		t.Fatalf("Expected the Tx to inherit the strict mode but got %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := All[requiredNote](ctx, &DB{db.DB}, db.Select().From("notes").Columns("id", "content")); err != nil {
+		t.Fatalf("Expected a new DB to not be strict but got %v", err)
+	}
+
+	for _, err := range Iterate[note](ctx, db, db.Select().From("notes").Columns("*", "1 AS extra")) {
+		if !errors.As(err, &mappingErr) {
+			t.Fatalf("Expected a MappingError but got %v", err)
+		}
+	}
+
+	if _, err := All[requiredNote](ctx, db, db.Select().From("notes")); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(*)"), &count); err != nil {
+		t.Fatalf("Expected scalars not to be checked but got %s", err)
+	}
+}
+
+func TestLoadDynamicFromDatabase(t *testing.T) {
+	db := createTestDB(t, `CREATE TABLE notes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name VARCHAR(64) NOT NULL UNIQUE,
+	content VARCHAR(255) NULL,
+	archived BOOLEAN NOT NULL DEFAULT 0,
+	score REAL NULL
+);`, `INSERT INTO notes (id, name, content, archived, score) VALUES
+	(1, "Fuu", "This is bar", 1, 1.5),
+	(2, "Test", NULL, 0, NULL)`)
+	defer db.Close()
+
+	ctx := context.TODO()
+	q := db.Select().From("notes").Columns("id", "name", "content", "archived", "score").OrderBy("id", "ASC")
+
+	maps := []map[string]interface{}{}
+	if _, err := db.Load(ctx, q, &maps); err != nil {
+		t.Fatal(err)
+	} else if expected := []map[string]interface{}{
+		{"id": int64(1), "name": "Fuu", "content": "This is bar", "archived": true, "score": 1.5},
+		{"id": int64(2), "name": "Test", "content": nil, "archived": false, "score": nil},
+	}; !reflect.DeepEqual(maps, expected) {
+		t.Fatalf("got: %v -- expected: %v", maps, expected)
+	}
+
+	row := map[string]interface{}{}
+	if _, err := db.Load(ctx, q, &row); err != nil {
+		t.Fatal(err)
+	} else if row["name"] != "Fuu" {
+		t.Fatalf("Expected the first row but got %v", row)
+	}
+
+	slices := [][]interface{}{}
+	if _, err := db.Load(ctx, q, &slices); err != nil {
+		t.Fatal(err)
+	} else if expected := []interface{}{int64(2), "Test", nil, false, nil}; len(slices) != 2 || !reflect.DeepEqual(slices[1], expected) {
+		t.Fatalf("got: %v -- expected: %v", slices, expected)
+	}
+
+	byID := map[int]note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Columns("id", "name"), &byID); err != nil {
+		t.Fatal(err)
+	} else if len(byID) != 2 || byID[1].Name != "Fuu" || byID[2].ID != 2 {
+		t.Fatalf("Expected notes keyed by id but got %v", byID)
+	}
+
+	byName := map[string]*note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Columns("name AS key", "id"), &byName); err != nil {
+		t.Fatal(err)
+	} else if len(byName) != 2 || byName["Test"].ID != 2 {
+		t.Fatalf("Expected notes keyed by name but got %v", byName)
+	}
+
+	names := map[int64]string{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Columns("id", "name"), &names); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(names, map[int64]string{1: "Fuu", 2: "Test"}) {
+		t.Fatalf("Expected names keyed by id but got %v", names)
+	}
+
+	if _, err := db.Load(ctx, db.Select().From("notes").Columns("id", "name", "content"), &names); err == nil {
+		t.Fatal("Expected an error for a map of scalars with more than 2 columns")
+	}
+}
+
+func TestWriteGuardsInDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu"),
+	(3, "Bar", "This is test")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+	count := 0
+
+	if _, err := db.Exec(ctx, db.Delete().From("notes")); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrUnboundedWrite)
+	}
+
+	if _, err := db.ExecReturning(ctx, db.Update().Table("notes").Set("content", "").Returning("id"), &[]note{}); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrUnboundedWrite)
+	}
+
+	if _, err := db.Exec(ctx, db.Update().Table("notes").Set("content", "updated").Where("id > ?", 1).MaxRows(1)); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrTooManyRows)
+	}
+
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(*)").Where("content = ?", "updated"), &count); err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Fatalf("Expected the update to be rolled back but %d notes were updated", count)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tx.Exec(ctx, tx.Insert().InTo("notes").Columns("name").Values("New")); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := []note{}
+	if _, err := tx.ExecReturning(ctx, tx.Delete().From("notes").Where("id > ?", 0).MaxRows(2).Returning("id"), &deleted); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrTooManyRows)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) {
+		t.Fatalf("Expected the transaction to be rolled back but got %v", err)
+	}
+
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(*)"), &count); err != nil {
+		t.Fatal(err)
+	} else if count != 3 {
+		t.Fatalf("Expected 3 notes but got %d", count)
+	}
+
+	n := note{}
+	if _, err := db.ExecReturning(ctx, db.Delete().From("notes").Where("id > ?", 0).MaxRows(1).Returning("id"), &n); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrTooManyRows)
+	}
+
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(*)"), &count); err != nil {
+		t.Fatal(err)
+	} else if count != 3 {
+		t.Fatalf("Expected the delete into a single struct to be rolled back but got %d notes", count)
+	}
+
+	ids := []int64{}
+	if _, err := db.Load(ctx, db.Delete().From("notes").Returning("id"), &ids); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrUnboundedWrite)
+	}
+
+	if _, err := db.Load(ctx, db.Delete().From("notes").Where("id > ?", 0).MaxRows(1).Returning("id"), &ids); !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrTooManyRows)
+	}
+
+	if _, err := db.Rows(ctx, db.Delete().From("notes").Where("id > ?", 0).MaxRows(1).Returning("id")); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrInvalidQuery)
+	}
+
+	if _, err := All[note](ctx, db, db.Update().Table("notes").Set("content", "").Returning("*")); !errors.Is(err, ErrUnboundedWrite) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrUnboundedWrite)
+	}
+
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("COUNT(*)"), &count); err != nil {
+		t.Fatal(err)
+	} else if count != 3 {
+		t.Fatalf("Expected the guarded loads to be rolled back but got %d notes", count)
+	}
+
+	if result, err := db.Exec(ctx, db.Delete().From("notes").All().MaxRows(3)); err != nil {
+		t.Fatal(err)
+	} else if affected, _ := result.RowsAffected(); affected != 3 {
+		t.Fatalf("Expected 3 notes to be deleted but got %d", affected)
+	}
+}
+
+func TestInsertRecordsWithSkippedFieldsIntoDatabase(t *testing.T) {
+	db := createTestDB(t, notesSchema, "")
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	type pn struct {
+		ID      int64  `db:"id,pk,autoincrement"`
+		Name    string `db:"name"`
+		Content string `db:"content,omitempty"`
+	}
+
+	if _, err := db.Exec(ctx, db.Insert().InTo("notes").Records([]pn{{Name: "a"}, {ID: 7, Name: "b", Content: "keep me"}})); err != nil {
+		t.Fatal(err)
+	}
+
+	n := note{}
+	if _, err := db.Load(ctx, db.Select().From("notes").Where("name = ?", "b"), &n); err != nil {
+		t.Fatal(err)
+	} else if n.ID != 7 || n.Content != "keep me" {
+		t.Fatalf("Expected note 7 with its content but got %v", n)
+	}
+
+	var id int64
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("id").Where("name = ?", "a"), &id); err != nil {
+		t.Fatal(err)
+	} else if id == 0 {
+		t.Fatal("Expected an id to be generated for note a")
+	}
+
+	if _, err := db.Exec(ctx, Expr("ALTER TABLE notes ADD COLUMN status TEXT NOT NULL DEFAULT 'new'")); err != nil {
+		t.Fatal(err)
+	}
+
+	type sn struct {
+		Name   string `db:"name"`
+		Status string `db:"status,omitempty"`
+	}
+
+	if _, err := db.Exec(ctx, db.Insert().InTo("notes").Record(sn{Name: "c"}).Record(sn{Name: "d", Status: "done"})); err != nil {
+		t.Fatal(err)
+	}
+
+	var status string
+	if err := db.LoadValue(ctx, db.Select().From("notes").Columns("status").Where("name = ?", "d"), &status); err != nil {
+		t.Fatal(err)
+	} else if status != "done" {
+		t.Fatalf("Expected the status of note d to be inserted but got %q", status)
+	}
+}
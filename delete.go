@@ -2,12 +2,20 @@ package qb
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 )
 
 // DeleteQuery represents a DELETE sql query
 type DeleteQuery struct {
+	withClause
 	whereClause
-	table string
+	table     string
+	orderBys  []string
+	limit     string
+	returning []string
+	all       bool
+	maxRows   int
 }
 
 // From is used to set the table to delete from
@@ -16,23 +24,107 @@ func (q *DeleteQuery) From(table string) *DeleteQuery {
 	return q
 }
 
-// Where adds a where clause to the delete query using *AND* strategy
-func (q *DeleteQuery) Where(condition string, params ...interface{}) *DeleteQuery {
+// Where adds a where clause to the delete query using *AND* strategy. The
+// condition is either a raw SQL string with params or a Builder such as qb.Or
+func (q *DeleteQuery) Where(condition interface{}, params ...interface{}) *DeleteQuery {
 	q.addWhere(condition, params...)
 	return q
 }
 
+// OrderBy adds an ORDER BY clause to the DELETE query, which determines the
+// rows deleted when combined with Limit
+func (q *DeleteQuery) OrderBy(column string, direction string) *DeleteQuery {
+	q.orderBys = append(q.orderBys, column+" "+direction)
+	return q
+}
+
+// Limit adds a LIMIT clause to the DELETE query. The table must have a rowid.
+func (q *DeleteQuery) Limit(limit int) *DeleteQuery {
+	q.limit = fmt.Sprintf("%d", limit)
+	return q
+}
+
+// Returning specifies which columns to return after the DELETE is successful
+func (q *DeleteQuery) Returning(returning ...string) *DeleteQuery {
+	q.returning = returning
+	return q
+}
+
+// All allows the DELETE query to be executed without a WHERE clause or LIMIT,
+// deleting all rows of the table
+func (q *DeleteQuery) All() *DeleteQuery {
+	q.all = true
+	return q
+}
+
+// MaxRows rolls back the surrounding transaction and returns ErrTooManyRows
+// if the DELETE query deletes more than max rows when executed. A max of 0
+// disables the guard, a negative max is rejected when the query is built.
+// Rows and Iterate return ErrInvalidQuery for a query with MaxRows.
+func (q *DeleteQuery) MaxRows(max int) *DeleteQuery {
+	q.maxRows = max
+	return q
+}
+
+func (q *DeleteQuery) hasReturning() bool {
+	return len(q.returning) > 0
+}
+
+func (q *DeleteQuery) unbounded() bool {
+	return !q.all && len(q.wheres) == 0 && q.limit == ""
+}
+
+func (q *DeleteQuery) maxRowsAffected() int {
+	return q.maxRows
+}
+
+// With adds a Common Table Expression to the beginning of the query. Wrap the
+// query in qb.Materialized or qb.NotMaterialized to add a hint.
+func (q *DeleteQuery) With(name string, query Builder) *DeleteQuery {
+	q.addWith(name, nil, query, false)
+	return q
+}
+
+// WithRecursive adds a recursive Common Table Expression with the given
+// columns to the beginning of the query
+func (q *DeleteQuery) WithRecursive(name string, columns []string, query Builder) *DeleteQuery {
+	q.addWith(name, columns, query, true)
+	return q
+}
+
 // Params returns the parameters for this query
 func (q *DeleteQuery) Params() []interface{} {
-	return q.whereClause.params
+	return append(q.withParams(), q.whereParams()...)
 }
 
 // Build renders the DELETE query as a string
 func (q *DeleteQuery) Build(buf *bytes.Buffer) error {
+	return annotate(q.build(buf), "DELETE", "")
+}
+
+func (q *DeleteQuery) build(buf *bytes.Buffer) error {
+	if q.table == "" {
+		return &BuildError{Clause: "FROM", Reason: "no table to delete from"}
+	}
+	if q.maxRows < 0 {
+		return &BuildError{Reason: fmt.Sprintf("MaxRows must not be negative but got %d", q.maxRows)}
+	}
+
+	if err := q.writeWith(buf); err != nil {
+		return err
+	}
+
 	buf.WriteString("DELETE FROM ")
 	buf.WriteString(q.table)
 
-	q.writeWhere(buf)
+	if err := q.writeLimitedWhere(buf, q.table, q.orderBys, q.limit); err != nil {
+		return err
+	}
+
+	if len(q.returning) > 0 {
+		buf.WriteString(" RETURNING ")
+		buf.WriteString(strings.Join(q.returning, ", "))
+	}
 
 	return nil
 }
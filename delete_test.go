@@ -2,6 +2,7 @@ package qb
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -24,6 +25,24 @@ func TestDeleteQuery(t *testing.T) {
 			},
 			result: "DELETE FROM fuu",
 		},
+		{
+			name: "delete without table",
+			query: func() *DeleteQuery {
+				query := &DeleteQuery{}
+				query.Where("id = ?", 1)
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "delete with negative max rows",
+			query: func() *DeleteQuery {
+				query := &DeleteQuery{table: "fuu"}
+				query.Where("id = ?", 1).MaxRows(-1)
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
 		{
 			name: "delete with where clause",
 			query: func() *DeleteQuery {
@@ -46,6 +65,40 @@ func TestDeleteQuery(t *testing.T) {
 			result: "DELETE FROM fuu WHERE column1 = ? AND column2 = ? AND column3 IS NULL",
 			values: []interface{}{1234, "test"},
 		},
+		{
+			name: "delete with limit and returning",
+			query: func() *DeleteQuery {
+				query := &DeleteQuery{table: "fuu"}
+				query.Where("created < ?", "2020-01-01")
+				query.OrderBy("created", "ASC")
+				query.Limit(1000)
+				query.Returning("id")
+				return query
+			},
+			result: "DELETE FROM fuu WHERE rowid IN (SELECT rowid FROM fuu WHERE created < ? ORDER BY created ASC LIMIT 1000) RETURNING id",
+			values: []interface{}{"2020-01-01"},
+		},
+		{
+			name: "delete with cte",
+			query: func() *DeleteQuery {
+				query := &DeleteQuery{table: "fuu"}
+				query.With("old", (&SelectQuery{table: "fuu"}).Columns("id").Where("year < ?", 2000))
+				query.Where("id IN (SELECT id FROM old)")
+				return query
+			},
+			result: "WITH old AS (SELECT id FROM fuu WHERE year < ?) DELETE FROM fuu WHERE id IN (SELECT id FROM old)",
+			values: []interface{}{2000},
+		},
+		{
+			name: "delete with predicate expression",
+			query: func() *DeleteQuery {
+				query := &DeleteQuery{table: "fuu"}
+				query.Where(Not(In("column1", 1, 2)))
+				return query
+			},
+			result: "DELETE FROM fuu WHERE NOT (column1 IN (?, ?))",
+			values: []interface{}{1, 2},
+		},
 	}
 
 	for _, tst := range testResults {
@@ -53,8 +106,10 @@ func TestDeleteQuery(t *testing.T) {
 			query := tst.query()
 			buf := bytes.Buffer{}
 
-			if err := query.Build(&buf); err != tst.err {
-				t.Fatal(err)
+			if err := query.Build(&buf); !errors.Is(err, tst.err) {
+				t.Fatalf("got: %v -- expected: %v", err, tst.err)
+			} else if tst.err != nil {
+				return
 			} else if buf.String() != tst.result {
 				t.Fatalf("got: %s -- expected: %s", buf.String(), tst.result)
 			} else if !reflect.DeepEqual(query.Params(), tst.values) {
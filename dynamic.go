@@ -0,0 +1,117 @@
+package qb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	typeRowMap   = reflect.TypeOf(map[string]interface{}{})
+	typeRowSlice = reflect.TypeOf([]interface{}{})
+)
+
+// isDynamicRow reports whether rows are scanned into t as a map of column
+// names to values or as a slice of values
+func isDynamicRow(t reflect.Type) bool {
+	return t == typeRowMap || t == typeRowSlice
+}
+
+// dynamicRow converts the values of a row scanned into interface{} values to
+// a value of type t, either a map[string]interface{} or an []interface{}
+func dynamicRow(t reflect.Type, columns []string, types []*sql.ColumnType, values []interface{}) reflect.Value {
+	if t == typeRowSlice {
+		row := make([]interface{}, len(values))
+		for i, value := range values {
+			row[i] = dynamicValue(value, types[i])
+		}
+		return reflect.ValueOf(row)
+	}
+	row := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		row[columns[i]] = dynamicValue(value, types[i])
+	}
+	return reflect.ValueOf(row)
+}
+
+// dynamicValue maps a value to a Go type according to the declared type of its
+// column. The driver already returns int64, float64, string, []byte, time.Time
+// or nil according to SQLite's type affinity, except for booleans which are
+// stored as integers.
+func dynamicValue(value interface{}, column *sql.ColumnType) interface{} {
+	if i, ok := value.(int64); ok {
+		switch strings.ToUpper(column.DatabaseTypeName()) {
+		case "BOOL", "BOOLEAN":
+			return i != 0
+		}
+	}
+	return value
+}
+
+// loadMap loads the rows into the map v keyed by the first column. A struct
+// value receives all columns, any other value receives the second column.
+func loadMap(rows *sql.Rows, columns []string, v reflect.Value, mode StrictMode) (int, error) {
+	keyType, valueType := v.Type().Key(), v.Type().Elem()
+
+	structType := valueType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	var plan *scanPlan
+	if structType.Kind() == reflect.Struct && !reflect.PointerTo(structType).Implements(typeScanner) {
+		plan = newScanPlan(columns, structType)
+		if mode != 0 {
+			if err := checkMapping(mode, structType, columns, plan); err != nil {
+				return 0, err
+			}
+		}
+	} else if len(columns) != 2 {
+		return 0, fmt.Errorf("qb: loading into %s requires 2 columns but got %d", v.Type(), len(columns))
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	ptrs := make([]interface{}, len(columns))
+	count := 0
+
+	for rows.Next() {
+		key := reflect.New(keyType).Elem()
+		value := reflect.New(valueType).Elem()
+
+		if plan == nil {
+			ptrs[0], ptrs[1] = key.Addr().Interface(), value.Addr().Interface()
+			if err := rows.Scan(ptrs...); err != nil {
+				return 0, err
+			}
+		} else {
+			target := value
+			if target.Kind() == reflect.Ptr {
+				target.Set(reflect.New(structType))
+				target = target.Elem()
+			}
+			if err := plan.scan(rows, target, ptrs); err != nil {
+				return 0, err
+			}
+			// Scan the first column once more into the key
+			for i := range ptrs {
+				ptrs[i] = dummyDest
+			}
+			ptrs[0] = key.Addr().Interface()
+			if err := rows.Scan(ptrs...); err != nil {
+				return 0, err
+			}
+		}
+
+		v.SetMapIndex(key, value)
+		count++
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, mapError(err)
+	}
+	return count, nil
+}
@@ -1,8 +1,189 @@
 package qb
 
-import "errors"
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
 
 var (
+	// ErrInvalidQuery indicates that Build was called for an invalid query, see BuildError
+	ErrInvalidQuery = errors.New("qb: invalid query")
+
 	// ErrInvalidPointer indicates that you passed an invalid pointer into a function
 	ErrInvalidPointer = errors.New("qb: attempt to load into an invalid pointer")
+
+	// ErrNoRows indicates that a query that should return a row returned none
+	ErrNoRows = errors.New("qb: no rows in result set")
+
+	// ErrNoReturning indicates that ExecReturning was called for a query without a RETURNING clause
+	ErrNoReturning = errors.New("qb: query has no RETURNING clause")
+
+	// ErrUnboundedWrite indicates that an UPDATE or DELETE query without a
+	// WHERE clause or LIMIT was executed without calling All
+	ErrUnboundedWrite = errors.New("qb: refusing to execute UPDATE or DELETE without WHERE or LIMIT, use All to allow")
+
+	// ErrTooManyRows indicates that an UPDATE or DELETE query affected more
+	// rows than allowed by MaxRows
+	ErrTooManyRows = errors.New("qb: query affected too many rows")
+
+	// ErrUniqueViolation indicates that a UNIQUE or PRIMARY KEY constraint failed
+	ErrUniqueViolation = errors.New("qb: unique constraint violation")
+
+	// ErrForeignKeyViolation indicates that a FOREIGN KEY constraint failed
+	ErrForeignKeyViolation = errors.New("qb: foreign key constraint violation")
+
+	// ErrNotNullViolation indicates that a NOT NULL constraint failed
+	ErrNotNullViolation = errors.New("qb: not null constraint violation")
+
+	// ErrCheckViolation indicates that a CHECK constraint failed
+	ErrCheckViolation = errors.New("qb: check constraint violation")
+
+	// ErrBusy indicates that the database file is locked by another connection
+	ErrBusy = errors.New("qb: database is busy")
+
+	// ErrReadOnly indicates an attempt to write to a read-only database
+	ErrReadOnly = errors.New("qb: database is read-only")
+)
+
+// BuildError is returned by Build when a query is invalid. It matches
+// ErrInvalidQuery when using errors.Is.
+type BuildError struct {
+	Query  string // the query type, such as INSERT
+	Clause string // the offending clause, such as VALUES
+	Reason string
+}
+
+func (e *BuildError) Error() string {
+	msg := "qb: "
+	for _, part := range []string{e.Query, e.Clause} {
+		if part != "" {
+			msg += part + ": "
+		}
+	}
+	return msg + e.Reason
+}
+
+// Is reports whether target is ErrInvalidQuery
+func (e *BuildError) Is(target error) bool {
+	return target == ErrInvalidQuery
+}
+
+// annotate fills in the query type and clause of a *BuildError returned while
+// rendering them, keeping those of nested queries and clauses
+func annotate(err error, query, clause string) error {
+	var e *BuildError
+	if errors.As(err, &e) {
+		if e.Query == "" {
+			e.Query = query
+		}
+		if e.Clause == "" {
+			e.Clause = clause
+		}
+	}
+	return err
+}
+
+// SQLite result codes, see https://www.sqlite.org/rescode.html
+const (
+	sqliteBusy                 = 5
+	sqliteReadOnly             = 8
+	sqliteConstraintCheck      = 275
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintNotNull    = 1299
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
 )
+
+// Error is a SQLite error mapped to one of the sentinel errors. Use errors.Is
+// to check for the sentinel and errors.As to inspect the details.
+type Error struct {
+	Err        error    // the sentinel error, such as ErrUniqueViolation
+	Code       int      // the extended SQLite result code
+	Table      string   // the table of the failed constraint, if known
+	Columns    []string // the columns of the failed constraint, if known
+	Constraint string   // the name or expression of a failed CHECK constraint
+	cause      error
+}
+
+func (e *Error) Error() string {
+	return e.cause.Error()
+}
+
+// Unwrap returns the sentinel error and the error returned by the driver
+func (e *Error) Unwrap() []error {
+	return []error{e.Err, e.cause}
+}
+
+// mapError maps an error returned by the driver to an *Error if its result
+// code corresponds to one of the sentinel errors
+func mapError(err error) error {
+	var coded interface{ Code() int }
+	if err == nil || !errors.As(err, &coded) {
+		return err
+	}
+
+	e := &Error{Code: coded.Code(), cause: err}
+	switch e.Code {
+	case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+		e.Err = ErrUniqueViolation
+	case sqliteConstraintForeignKey:
+		e.Err = ErrForeignKeyViolation
+	case sqliteConstraintNotNull:
+		e.Err = ErrNotNullViolation
+	case sqliteConstraintCheck:
+		e.Err = ErrCheckViolation
+	default:
+		switch e.Code & 0xff {
+		case sqliteBusy:
+			e.Err = ErrBusy
+		case sqliteReadOnly:
+			e.Err = ErrReadOnly
+		}
+	}
+	if e.Err == nil {
+		return err
+	}
+
+	// The message reads as "UNIQUE constraint failed: animals.name (2067)",
+	// listing comma separated columns or the name of a CHECK constraint
+	msg := err.Error()
+	if i := strings.LastIndex(msg, "constraint failed: "); i >= 0 {
+		detail := msg[i+len("constraint failed: "):]
+		if j := strings.LastIndex(detail, " ("); j >= 0 {
+			detail = detail[:j]
+		}
+		if e.Err == ErrCheckViolation {
+			e.Constraint = detail
+		} else {
+			for _, column := range strings.Split(detail, ", ") {
+				table, name, ok := strings.Cut(column, ".")
+				if !ok {
+					continue
+				}
+				e.Table = table
+				e.Columns = append(e.Columns, name)
+			}
+		}
+	}
+	return e
+}
+
+// MappingError is returned in strict mode when the result columns of a query
+// do not match the fields of the struct the rows are loaded into
+type MappingError struct {
+	Type    reflect.Type
+	Columns []string // result columns without a matching field
+	Fields  []string // required fields without a matching column
+}
+
+func (e *MappingError) Error() string {
+	var problems []string
+	if len(e.Columns) > 0 {
+		problems = append(problems, "unmapped columns "+strings.Join(e.Columns, ", "))
+	}
+	if len(e.Fields) > 0 {
+		problems = append(problems, "missing required fields "+strings.Join(e.Fields, ", "))
+	}
+	return "qb: cannot load into " + e.Type.String() + ": " + strings.Join(problems, "; ")
+}
@@ -0,0 +1,114 @@
+package qb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestConstraintErrors(t *testing.T) {
+	db := createTestDB(t, `PRAGMA foreign_keys = ON;
+CREATE TABLE artist (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+CREATE TABLE track (
+	id INTEGER PRIMARY KEY,
+	artist INTEGER REFERENCES artist(id),
+	title TEXT NOT NULL,
+	position INTEGER CONSTRAINT positive_position CHECK (position > 0),
+	UNIQUE (artist, title)
+);`, `INSERT INTO artist VALUES (1, 'Dean Martin');
+INSERT INTO track VALUES (11, 1, 'That is Amore', 1);`)
+	defer db.Close()
+
+	type test struct {
+		name       string
+		query      Builder
+		sentinel   error
+		table      string
+		columns    []string
+		constraint string
+	}
+
+	var testResults = []test{
+		{
+			name:     "primary key",
+			query:    db.Insert().InTo("artist").Columns("id", "name").Values(1, "Frank Sinatra"),
+			sentinel: ErrUniqueViolation,
+			table:    "artist",
+			columns:  []string{"id"},
+		},
+		{
+			name:     "unique",
+			query:    db.Insert().InTo("track").Columns("artist", "title").Values(1, "That is Amore"),
+			sentinel: ErrUniqueViolation,
+			table:    "track",
+			columns:  []string{"artist", "title"},
+		},
+		{
+			name:     "not null",
+			query:    db.Update().Table("artist").Set("name", nil).Where("id = ?", 1),
+			sentinel: ErrNotNullViolation,
+			table:    "artist",
+			columns:  []string{"name"},
+		},
+		{
+			name:       "check",
+			query:      db.Insert().InTo("track").Columns("artist", "title", "position").Values(1, "My Way", 0),
+			sentinel:   ErrCheckViolation,
+			constraint: "positive_position",
+		},
+		{
+			name:     "foreign key",
+			query:    db.Insert().InTo("track").Columns("artist", "title").Values(2, "My Way"),
+			sentinel: ErrForeignKeyViolation,
+		},
+	}
+
+	for _, tst := range testResults {
+		t.Run(tst.name, func(t *testing.T) {
+			_, err := db.Exec(context.Background(), tst.query)
+
+			var e *Error
+			if !errors.Is(err, tst.sentinel) {
+				t.Fatalf("got: %v -- expected: %s", err, tst.sentinel)
+			} else if !errors.As(err, &e) {
+				t.Fatalf("Expected a *qb.Error but got %T", err)
+			} else if e.Table != tst.table || !reflect.DeepEqual(e.Columns, tst.columns) || e.Constraint != tst.constraint {
+				t.Fatalf("got: %s %v %s -- expected: %s %v %s", e.Table, e.Columns, e.Constraint, tst.table, tst.columns, tst.constraint)
+			}
+		})
+	}
+}
+
+func TestLoadValueNoRows(t *testing.T) {
+	db := createTestDB(t, notesSchema, "")
+	defer db.Close()
+
+	var name string
+	if err := db.LoadValue(context.Background(), db.Select().From("notes").Columns("name"), &name); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrNoRows)
+	}
+}
+
+func TestReadOnlyError(t *testing.T) {
+	ctx := context.Background()
+	path := t.TempDir() + "/test.db"
+
+	db, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.DB.Exec(notesSchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if db, err = Open(ctx, "file:"+path+"?mode=ro"); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(ctx, db.Insert().InTo("notes").Columns("name").Values("fuu")); !errors.Is(err, ErrReadOnly) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrReadOnly)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 )
@@ -17,45 +20,204 @@ type loggedRunner struct {
 func (r loggedRunner) ExecContext(ctx context.Context, q string, args ...interface{}) (sql.Result, error) {
 	logger := GetLoggerCtx(ctx)
 	if logger == nil {
-		return r.inner.ExecContext(ctx, q, args...)
+		result, err := r.inner.ExecContext(ctx, q, args...)
+		return result, mapError(err)
 	}
 	start := time.Now()
 	result, err := r.inner.ExecContext(ctx, q, args...)
 	logger(ctx, time.Since(start), "%s -- %v", q, args)
-	return result, err
+	return result, mapError(err)
 }
 
 func (r loggedRunner) QueryContext(ctx context.Context, q string, args ...interface{}) (*sql.Rows, error) {
 	logger := GetLoggerCtx(ctx)
 	if logger == nil {
-		return r.inner.QueryContext(ctx, q, args...)
+		rows, err := r.inner.QueryContext(ctx, q, args...)
+		return rows, mapError(err)
 	}
 	start := time.Now()
 	rows, err := r.inner.QueryContext(ctx, q, args...)
 	logger(ctx, time.Since(start), "%s -- %v", q, args)
-	return rows, err
+	return rows, mapError(err)
 }
 
 var bufPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
 
+// query executes builder and scans the resulting rows into dest. An UPDATE or
+// DELETE query with RETURNING is subject to the same guards as in exec.
 func query(ctx context.Context, r runner, builder Builder, dest interface{}) (int, error) {
+	max, err := checkGuard(builder)
+	if err != nil {
+		return 0, err
+	}
+	if max == 0 {
+		return loadStatement(ctx, r, builder, dest)
+	}
+
+	count := 0
+	err = inTx(ctx, r, func(r runner) error {
+		var err error
+		if count, err = loadStatement(ctx, r, builder, dest); err != nil {
+			return err
+		}
+		// load stops after the first row when dest is not a slice while
+		// SQLite applies the whole statement, so count the affected rows
+		affected, err := changes(ctx, r)
+		if err != nil {
+			return err
+		}
+		return checkAffected(affected, max)
+	})
+	if err != nil {
+		return 0, rollbackGuarded(r, err)
+	}
+	return count, nil
+}
+
+func loadStatement(ctx context.Context, r runner, builder Builder, dest interface{}) (int, error) {
+	rows, err := queryStatement(ctx, r, builder)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := load(rows, dest, strictModeOf(ctx, builder))
+	if err != nil {
+		return 0, err
+	}
+
+	if q, ok := builder.(*SelectQuery); ok && len(q.preloads) > 0 && count > 0 {
+		if err := preload(ctx, r, dest, q.preloads); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// queryRows executes builder and returns its rows for the caller to iterate.
+// The number of rows affected by a write is only known once the rows are
+// consumed, so MaxRows is not supported here.
+func queryRows(ctx context.Context, r runner, builder Builder) (*sql.Rows, error) {
+	max, err := checkGuard(builder)
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 {
+		return nil, fmt.Errorf("%w: MaxRows requires Load or ExecReturning", ErrInvalidQuery)
+	}
+	return queryStatement(ctx, r, builder)
+}
+
+func queryStatement(ctx context.Context, r runner, builder Builder) (*sql.Rows, error) {
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufPool.Put(buf)
 
 	if err := builder.Build(buf); err != nil {
-		return 0, err
+		return nil, err
+	}
+
+	return loggedRunner{r}.QueryContext(ctx, buf.String(), builder.Params()...)
+}
+
+// maxVariables is the maximum number of parameters SQLite accepts in a single
+// statement (SQLITE_MAX_VARIABLE_NUMBER)
+var maxVariables = 32766
+
+// batcher is implemented by builders that can split themselves into multiple
+// statements to stay below maxVariables
+type batcher interface {
+	batches(limit int) []Builder
+}
+
+// guarded is implemented by UPDATE and DELETE queries to protect against
+// writes that affect more rows than intended
+type guarded interface {
+	unbounded() bool
+	maxRowsAffected() int
+}
+
+// checkGuard returns ErrUnboundedWrite if builder is an UPDATE or DELETE query
+// without a WHERE clause or LIMIT, and the maximum number of rows it may
+// affect or 0 if it is unlimited
+func checkGuard(builder Builder) (int, error) {
+	g, ok := builder.(guarded)
+	if !ok {
+		return 0, nil
+	}
+	if g.unbounded() {
+		return 0, ErrUnboundedWrite
+	}
+	return g.maxRowsAffected(), nil
+}
+
+// checkAffected returns ErrTooManyRows if more than max rows were affected
+func checkAffected(affected int64, max int) error {
+	if affected <= int64(max) {
+		return nil
 	}
+	return fmt.Errorf("%w: %d rows affected but at most %d allowed", ErrTooManyRows, affected, max)
+}
 
-	rows, err := loggedRunner{r}.QueryContext(ctx, buf.String(), builder.Params()...)
+// rollbackGuarded rolls back r if it is a transaction supplied by the caller
+// and err is ErrTooManyRows, so the guarded write is never committed.
+// Transactions started by inTx are rolled back by inTx itself.
+func rollbackGuarded(r runner, err error) error {
+	tx, ok := r.(*sql.Tx)
+	if !ok || !errors.Is(err, ErrTooManyRows) {
+		return err
+	}
+	if rbErr := tx.Rollback(); rbErr != nil {
+		return fmt.Errorf("%w (rollback failed: %w)", err, rbErr)
+	}
+	return err
+}
+
+// changes returns the number of rows affected by the last write statement on
+// the connection of r, which must be a transaction
+func changes(ctx context.Context, r runner) (int64, error) {
+	rows, err := r.QueryContext(ctx, "SELECT changes()")
 	if err != nil {
 		return 0, err
 	}
-
-	return load(rows, dest)
+	var affected int64
+	_, err = load(rows, &affected, 0)
+	return affected, err
 }
 
 func exec(ctx context.Context, r runner, builder Builder) (sql.Result, error) {
+	max, err := checkGuard(builder)
+	if err != nil {
+		return nil, err
+	}
+	if max == 0 {
+		return execStatement(ctx, r, builder)
+	}
+
+	var result sql.Result
+	err = inTx(ctx, r, func(r runner) error {
+		if result, err = execStatement(ctx, r, builder); err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		return checkAffected(affected, max)
+	})
+	if err != nil {
+		return nil, rollbackGuarded(r, err)
+	}
+	return result, nil
+}
+
+func execStatement(ctx context.Context, r runner, builder Builder) (sql.Result, error) {
+	if b, ok := builder.(batcher); ok {
+		if batches := b.batches(maxVariables); len(batches) > 1 {
+			return execBatches(ctx, r, batches)
+		}
+	}
+
 	buf := bufPool.Get().(*bytes.Buffer)
 	buf.Reset()
 	defer bufPool.Put(buf)
@@ -67,57 +229,236 @@ func exec(ctx context.Context, r runner, builder Builder) (sql.Result, error) {
 	return loggedRunner{r}.ExecContext(ctx, buf.String(), builder.Params()...)
 }
 
-// scanPlan is a precomputed mapping from result column positions to struct field paths.
-// A nil entry means no matching field (use dummyDest).
-type scanPlan [][]int
+// execBatches executes all batches within a single transaction
+func execBatches(ctx context.Context, r runner, batches []Builder) (sql.Result, error) {
+	var total batchResult
+	err := inTx(ctx, r, func(r runner) error {
+		for _, batch := range batches {
+			result, err := execStatement(ctx, r, batch)
+			if err != nil {
+				return err
+			}
+			if total.lastInsertID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			total.rowsAffected += affected
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// returner is implemented by write queries that support a RETURNING clause
+type returner interface {
+	hasReturning() bool
+}
 
-func newScanPlan(columns []string, t reflect.Type) scanPlan {
-	nameToPath := make(map[string][]int)
-	buildFieldPaths(t, nil, nameToPath)
+func execReturning(ctx context.Context, r runner, builder Builder, dest interface{}) (int, error) {
+	if q, ok := builder.(returner); ok && !q.hasReturning() {
+		return 0, ErrNoReturning
+	}
 
-	plan := make(scanPlan, len(columns))
-	for i, col := range columns {
-		plan[i] = nameToPath[col]
+	if b, ok := builder.(batcher); ok {
+		if batches := b.batches(maxVariables); len(batches) > 1 {
+			return queryBatches(ctx, r, batches, dest)
+		}
 	}
-	return plan
+
+	return query(ctx, r, builder, dest)
+}
+
+// queryBatches executes all batches within a single transaction and scans
+// their results into dest
+func queryBatches(ctx context.Context, r runner, batches []Builder, dest interface{}) (int, error) {
+	total := 0
+	err := inTx(ctx, r, func(r runner) error {
+		for _, batch := range batches {
+			count, err := query(ctx, r, batch, dest)
+			if err != nil {
+				return err
+			}
+			total += count
+		}
+		return nil
+	})
+	return total, err
 }
 
-func buildFieldPaths(t reflect.Type, prefix []int, out map[string][]int) {
-	if reflect.PointerTo(t).Implements(typeValuer) || t.Implements(typeValuer) {
-		return
+// inTx calls fn within a transaction. If r is not already a transaction a new
+// one is started and committed when fn succeeds or rolled back when it fails.
+func inTx(ctx context.Context, r runner, fn func(runner) error) error {
+	db, ok := r.(*sql.DB)
+	if !ok {
+		return fn(r)
 	}
-	for i := 0; i < t.NumField(); i++ {
-		field := t.Field(i)
-		if field.PkgPath != "" && !field.Anonymous {
-			continue
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return mapError(tx.Commit())
+}
+
+// batchResult aggregates the results of multiple statements
+type batchResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+func (r batchResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r batchResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// scanPlan is a precomputed mapping from result column positions to struct
+// field paths
+type scanPlan struct {
+	// paths holds the field path per column, nil means no matching field (use dummyDest)
+	paths [][]int
+	// optionals holds the paths of pointers to structs tagged with prefix,
+	// which are left nil when all their columns are NULL
+	optionals [][]int
+	// members holds per column the positions in optionals of the pointers on its path
+	members [][]int
+}
+
+type scanPlanKey struct {
+	t       reflect.Type
+	columns string
+}
+
+var scanPlanCache sync.Map // map[scanPlanKey]*scanPlan
+
+// newScanPlan returns the plan to scan columns into the struct type t, which
+// is computed once per type and column set and cached for subsequent calls
+func newScanPlan(columns []string, t reflect.Type) *scanPlan {
+	key := scanPlanKey{t: t, columns: strings.Join(columns, "\x00")}
+	if plan, ok := scanPlanCache.Load(key); ok {
+		return plan.(*scanPlan)
+	}
+
+	info := getStructInfo(t)
+	plan := &scanPlan{paths: make([][]int, len(columns)), members: make([][]int, len(columns))}
+	for i, col := range columns {
+		if j, ok := info.byName[col]; ok {
+			plan.paths[i] = info.fields[j].index
+			plan.members[i] = info.fields[j].optionals
+			if len(plan.members[i]) > 0 {
+				plan.optionals = info.optionals
+			}
 		}
-		tag := field.Tag.Get("db")
-		if tag == "-" {
-			continue
+	}
+
+	actual, _ := scanPlanCache.LoadOrStore(key, plan)
+	return actual.(*scanPlan)
+}
+
+// scan scans the current row into the struct target following the plan
+func (plan *scanPlan) scan(rows *sql.Rows, target reflect.Value, ptrs []interface{}) error {
+	var null []bool
+	if plan.optionals != nil {
+		var err error
+		if null, err = plan.nullOptionals(rows, ptrs); err != nil {
+			return err
 		}
-		if tag == "" {
-			tag = camelCaseToSnakeCase(field.Name)
+		for j, isNull := range null {
+			if f, ok := fieldByIndex(target, plan.optionals[j]); ok && isNull {
+				f.Set(reflect.Zero(f.Type()))
+			}
 		}
+	}
+	plan.fill(target, ptrs, null)
+	return rows.Scan(ptrs...)
+}
 
-		path := make([]int, len(prefix)+1)
-		copy(path, prefix)
-		path[len(prefix)] = i
+// nullOptionals scans the current row to report for each optional struct
+// whether all its columns are NULL
+func (plan *scanPlan) nullOptionals(rows *sql.Rows, ptrs []interface{}) ([]bool, error) {
+	checks := make([]nullCheck, len(plan.paths))
+	for i := range plan.paths {
+		if len(plan.members[i]) > 0 {
+			ptrs[i] = &checks[i]
+		} else {
+			ptrs[i] = dummyDest
+		}
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
 
-		if _, exists := out[tag]; !exists {
-			out[tag] = path
+	null := make([]bool, len(plan.optionals))
+	for j := range null {
+		null[j] = true
+	}
+	for i, members := range plan.members {
+		if checks[i].valid {
+			for _, j := range members {
+				null[j] = false
+			}
 		}
+	}
+	return null, nil
+}
 
-		ft := field.Type
-		if ft.Kind() == reflect.Ptr {
-			ft = ft.Elem()
+// fill sets ptrs to the addresses of the fields of the struct target following
+// the plan, allocating nil pointers to nested structs along the way. Columns of
+// optional structs reported as null are skipped.
+func (plan *scanPlan) fill(target reflect.Value, ptrs []interface{}, null []bool) {
+	for i, path := range plan.paths {
+		if path == nil || plan.isNull(i, null) {
+			ptrs[i] = dummyDest
+			continue
 		}
-		if ft.Kind() == reflect.Struct {
-			buildFieldPaths(ft, path, out)
+		f := target
+		for _, idx := range path {
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					f.Set(reflect.New(f.Type().Elem()))
+				}
+				f = f.Elem()
+			}
+			f = f.Field(idx)
+		}
+		ptrs[i] = f.Addr().Interface()
+	}
+}
+
+func (plan *scanPlan) isNull(column int, null []bool) bool {
+	if null == nil {
+		return false
+	}
+	for _, j := range plan.members[column] {
+		if null[j] {
+			return true
 		}
 	}
+	return false
+}
+
+// nullCheck is a sql.Scanner that records whether a column is NULL
+type nullCheck struct {
+	valid bool
+}
+
+func (n *nullCheck) Scan(src interface{}) error {
+	n.valid = src != nil
+	return nil
 }
 
-func load(rows *sql.Rows, value interface{}) (int, error) {
+func load(rows *sql.Rows, value interface{}, mode StrictMode) (int, error) {
 	defer rows.Close()
 
 	columns, err := rows.Columns()
@@ -131,7 +472,11 @@ func load(rows *sql.Rows, value interface{}) (int, error) {
 	}
 	v = v.Elem()
 
-	isSlice := v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+	if v.Kind() == reflect.Map && v.Type() != typeRowMap {
+		return loadMap(rows, columns, v, mode)
+	}
+
+	isSlice := v.Kind() == reflect.Slice && v.Type() != typeRowSlice && v.Type().Elem().Kind() != reflect.Uint8
 
 	var elemType reflect.Type
 	if isSlice {
@@ -140,20 +485,11 @@ func load(rows *sql.Rows, value interface{}) (int, error) {
 		elemType = v.Type()
 	}
 
-	// Dereference pointer element type for plan building
-	baseType := elemType
-	if baseType.Kind() == reflect.Ptr {
-		baseType = baseType.Elem()
-	}
-
-	// Precompute column→field plan for structs; fall back to findPtr for scanners/scalars
-	var plan scanPlan
-	if baseType.Kind() == reflect.Struct && !reflect.PointerTo(baseType).Implements(typeScanner) {
-		plan = newScanPlan(columns, baseType)
+	scanner := newRowScanner(columns, elemType)
+	if err := scanner.check(mode); err != nil {
+		return 0, err
 	}
 
-	// Pre-allocate the ptrs slice once and reuse across rows
-	ptrs := make([]interface{}, len(columns))
 	count := 0
 
 	for rows.Next() {
@@ -164,42 +500,8 @@ func load(rows *sql.Rows, value interface{}) (int, error) {
 			elem = v
 		}
 
-		if plan != nil {
-			target := elem
-			if target.Kind() == reflect.Ptr {
-				if target.IsNil() {
-					target.Set(reflect.New(target.Type().Elem()))
-				}
-				target = target.Elem()
-			}
-			for i, path := range plan {
-				if path == nil {
-					ptrs[i] = dummyDest
-					continue
-				}
-				f := target
-				for _, idx := range path {
-					if f.Kind() == reflect.Ptr {
-						if f.IsNil() {
-							f.Set(reflect.New(f.Type().Elem()))
-						}
-						f = f.Elem()
-					}
-					f = f.Field(idx)
-				}
-				ptrs[i] = f.Addr().Interface()
-			}
-			if err = rows.Scan(ptrs...); err != nil {
-				return 0, err
-			}
-		} else {
-			p, err := findPtr(columns, elem)
-			if err != nil {
-				return 0, err
-			}
-			if err = rows.Scan(p...); err != nil {
-				return 0, err
-			}
+		if err := scanner.scan(rows, elem); err != nil {
+			return 0, err
 		}
 
 		count++
@@ -211,11 +513,110 @@ func load(rows *sql.Rows, value interface{}) (int, error) {
 	}
 
 	if err := rows.Err(); err != nil {
-		return 0, err
+		return 0, mapError(err)
 	}
 	return count, nil
 }
 
+// rowScanner scans rows into values of a single type
+type rowScanner struct {
+	t       reflect.Type
+	columns []string
+	plan    *scanPlan
+	ptrs    []interface{}
+	dynamic bool
+	types   []*sql.ColumnType
+	values  []interface{}
+}
+
+func newRowScanner(columns []string, t reflect.Type) *rowScanner {
+	s := &rowScanner{columns: columns}
+
+	// Dereference pointer element type for plan building
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	// Scan maps and slices of values through interface{} values
+	if isDynamicRow(t) {
+		s.t = t
+		s.dynamic = true
+		s.values = make([]interface{}, len(columns))
+		s.ptrs = make([]interface{}, len(columns))
+		for i := range s.values {
+			s.ptrs[i] = &s.values[i]
+		}
+		return s
+	}
+
+	// Precompute column→field plan for structs; fall back to findPtr for scanners/scalars
+	if t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(typeScanner) {
+		s.t = t
+		s.plan = newScanPlan(columns, t)
+		// Pre-allocate the ptrs slice once and reuse across rows
+		s.ptrs = make([]interface{}, len(columns))
+	}
+
+	return s
+}
+
+// check returns a *MappingError if the columns do not match the struct fields
+// according to mode. Rows scanned into anything but a struct are not checked.
+func (s *rowScanner) check(mode StrictMode) error {
+	if mode == 0 || s.plan == nil {
+		return nil
+	}
+	return checkMapping(mode, s.t, s.columns, s.plan)
+}
+
+// scan scans the current row into elem, which must be settable
+func (s *rowScanner) scan(rows *sql.Rows, elem reflect.Value) error {
+	if s.dynamic {
+		return s.scanDynamic(rows, elem)
+	}
+
+	if s.plan == nil {
+		p, err := findPtr(s.columns, elem)
+		if err != nil {
+			return err
+		}
+		return rows.Scan(p...)
+	}
+
+	target := elem
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	return s.plan.scan(rows, target, s.ptrs)
+}
+
+// scanDynamic scans the current row into elem, a map[string]interface{} or an
+// []interface{} or a pointer to either
+func (s *rowScanner) scanDynamic(rows *sql.Rows, elem reflect.Value) error {
+	if s.types == nil {
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		s.types = types
+	}
+	if err := rows.Scan(s.ptrs...); err != nil {
+		return err
+	}
+	row := dynamicRow(s.t, s.columns, s.types, s.values)
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			elem.Set(reflect.New(s.t))
+		}
+		elem = elem.Elem()
+	}
+	elem.Set(row)
+	return nil
+}
+
 type dummyScanner struct{}
 
 func (dummyScanner) Scan(interface{}) error {
@@ -233,15 +634,8 @@ func findPtr(column []string, value reflect.Value) ([]interface{}, error) {
 	}
 	switch value.Kind() {
 	case reflect.Struct:
-		var ptr []interface{}
-		m := structMap(value)
-		for _, key := range column {
-			if val, ok := m[key]; ok {
-				ptr = append(ptr, val.Addr().Interface())
-			} else {
-				ptr = append(ptr, dummyDest)
-			}
-		}
+		ptr := make([]interface{}, len(column))
+		newScanPlan(column, value.Type()).fill(value, ptr, nil)
 		return ptr, nil
 	case reflect.Ptr:
 		if value.IsNil() {
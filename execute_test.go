@@ -0,0 +1,56 @@
+package qb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+var typeOfNote = reflect.TypeOf(note{})
+
+func BenchmarkLoad(b *testing.B) {
+	db, err := Open(context.TODO(), ":memory:")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err = db.DB.Exec(notesSchema); err != nil {
+		b.Fatal(err)
+	}
+	if _, err = db.DB.Exec(`INSERT INTO notes (id, name, content) VALUES (1, "Fuu", "This is bar")`); err != nil {
+		b.Fatal(err)
+	}
+
+	ctx := context.TODO()
+	q := db.Select().From("notes").Where("id = ?", 1)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		n := note{}
+		if _, err := db.Load(ctx, q, &n); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecord(b *testing.B) {
+	n := note{ID: 1, Name: "Fuu", Content: "This is bar"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		(&InsertQuery{table: "notes"}).Columns("id", "name", "content").Record(&n)
+	}
+}
+
+func BenchmarkScanPlan(b *testing.B) {
+	columns := []string{"id", "name", "content"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newRowScanner(columns, typeOfNote)
+	}
+}
@@ -0,0 +1,548 @@
+package qb
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Expr creates a raw SQL expression with optional parameters
+func Expr(query string, params ...interface{}) Builder {
+	return &rawExpr{query: query, params: params}
+}
+
+type rawExpr struct {
+	query  string
+	params []interface{}
+}
+
+func (e *rawExpr) Build(buf *bytes.Buffer) error {
+	if n, ok := countPlaceholders(e.query); ok && n != len(e.params) {
+		return &BuildError{Reason: fmt.Sprintf("expression %q has %d placeholders but %d params", e.query, n, len(e.params))}
+	}
+
+	if !hasExpandableParam(e.params) {
+		buf.WriteString(e.query)
+		return nil
+	}
+
+	// Expand every ? bound to a slice into as many placeholders as the
+	// slice has elements and every ? bound to a Builder into its SQL,
+	// skipping over quoted strings, identifiers and comments
+	index, start := 0, 0
+	for i := 0; i < len(e.query); i++ {
+		if end := skipLiteral(e.query, i); end > i {
+			i = end - 1
+			continue
+		}
+		if e.query[i] != '?' || index >= len(e.params) {
+			continue
+		}
+		param := e.params[index]
+		index++
+		if b, ok := param.(Builder); ok {
+			buf.WriteString(e.query[start:i])
+			if err := writeSubquery(buf, b); err != nil {
+				return err
+			}
+			start = i + 1
+		} else if slice, ok := sliceParam(param); ok {
+			buf.WriteString(e.query[start:i])
+			writePlaceholders(buf, slice.Len())
+			start = i + 1
+		}
+	}
+	buf.WriteString(e.query[start:])
+	return nil
+}
+
+// countPlaceholders returns the number of ? placeholders in query outside of
+// quoted strings, identifiers and comments. It reports false if query uses
+// numbered or named parameters such as ?1 or :name, which cannot be counted
+// this way.
+func countPlaceholders(query string) (int, bool) {
+	n := 0
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i); end > i {
+			i = end - 1
+			continue
+		}
+		c := query[i]
+		if c == '?' || c == ':' || c == '@' || c == '$' {
+			if i+1 < len(query) && isParamNameChar(query[i+1]) {
+				return 0, false
+			}
+			if c == '?' {
+				n++
+			}
+		}
+	}
+	return n, true
+}
+
+// skipLiteral returns the index just past the quoted string, identifier or
+// comment starting at query[i], or i if none starts there. An unterminated
+// one runs to the end of query.
+func skipLiteral(query string, i int) int {
+	var open, close string
+	switch {
+	case query[i] == '\'' || query[i] == '"' || query[i] == '`':
+		open, close = query[i:i+1], query[i:i+1]
+	case query[i] == '[':
+		open, close = "[", "]"
+	case strings.HasPrefix(query[i:], "--"):
+		open, close = "--", "\n"
+	case strings.HasPrefix(query[i:], "/*"):
+		open, close = "/*", "*/"
+	default:
+		return i
+	}
+	start := i + len(open)
+	if end := strings.Index(query[start:], close); end >= 0 {
+		return start + end + len(close)
+	}
+	return len(query)
+}
+
+func isParamNameChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func (e *rawExpr) Params() []interface{} {
+	return expandParams(e.params)
+}
+
+// And combines the given conditions using AND
+func And(conditions ...Builder) Builder {
+	return &junction{op: " AND ", empty: "1", conditions: conditions}
+}
+
+// Or combines the given conditions using OR
+func Or(conditions ...Builder) Builder {
+	return &junction{op: " OR ", empty: "0", conditions: conditions}
+}
+
+type junction struct {
+	op         string
+	empty      string
+	conditions []Builder
+}
+
+func (j *junction) Build(buf *bytes.Buffer) error {
+	switch len(j.conditions) {
+	case 0:
+		buf.WriteString(j.empty)
+		return nil
+	case 1:
+		return j.conditions[0].Build(buf)
+	}
+	buf.WriteString("(")
+	if err := writeJoined(buf, j.conditions, j.op); err != nil {
+		return err
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+func (j *junction) Params() []interface{} {
+	return joinedParams(j.conditions)
+}
+
+// Not negates the given condition
+func Not(condition Builder) Builder {
+	return &notExpr{condition}
+}
+
+type notExpr struct {
+	condition Builder
+}
+
+func (n *notExpr) Build(buf *bytes.Buffer) error {
+	if j, ok := n.condition.(*junction); ok && len(j.conditions) > 1 {
+		buf.WriteString("NOT ")
+		return j.Build(buf)
+	}
+	buf.WriteString("NOT (")
+	if err := n.condition.Build(buf); err != nil {
+		return err
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+func (n *notExpr) Params() []interface{} {
+	return n.condition.Params()
+}
+
+// Eq creates a column = value condition, or column IS NULL if value is nil. A
+// Builder value such as qb.Expr("other.column") is rendered as an expression.
+func Eq(column string, value interface{}) Builder {
+	if value == nil {
+		return IsNull(column)
+	}
+	return &compareExpr{column: column, op: " = ", value: value}
+}
+
+// Neq creates a column != value condition, or column IS NOT NULL if value is nil
+func Neq(column string, value interface{}) Builder {
+	if value == nil {
+		return IsNotNull(column)
+	}
+	return &compareExpr{column: column, op: " != ", value: value}
+}
+
+// Gt creates a column > value condition
+func Gt(column string, value interface{}) Builder {
+	return &compareExpr{column: column, op: " > ", value: value}
+}
+
+// Gte creates a column >= value condition
+func Gte(column string, value interface{}) Builder {
+	return &compareExpr{column: column, op: " >= ", value: value}
+}
+
+// Lt creates a column < value condition
+func Lt(column string, value interface{}) Builder {
+	return &compareExpr{column: column, op: " < ", value: value}
+}
+
+// Lte creates a column <= value condition
+func Lte(column string, value interface{}) Builder {
+	return &compareExpr{column: column, op: " <= ", value: value}
+}
+
+// Like creates a column LIKE pattern condition
+func Like(column string, pattern string) Builder {
+	return &compareExpr{column: column, op: " LIKE ", value: pattern}
+}
+
+// NotLike creates a column NOT LIKE pattern condition
+func NotLike(column string, pattern string) Builder {
+	return &compareExpr{column: column, op: " NOT LIKE ", value: pattern}
+}
+
+type compareExpr struct {
+	column string
+	op     string
+	value  interface{}
+	wrap   bool
+}
+
+func (c *compareExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString(c.column)
+	buf.WriteString(c.op)
+	if b, ok := c.value.(Builder); ok {
+		if c.wrap {
+			return writeSubquery(buf, b)
+		}
+		return writeExpr(buf, b)
+	}
+	buf.WriteString("?")
+	return nil
+}
+
+func (c *compareExpr) Params() []interface{} {
+	if b, ok := c.value.(Builder); ok {
+		return b.Params()
+	}
+	return []interface{}{c.value}
+}
+
+// In creates a column IN (values...) condition. A single Builder value is
+// rendered as a subquery.
+func In(column string, values ...interface{}) Builder {
+	if len(values) == 1 {
+		if b, ok := values[0].(Builder); ok {
+			return &compareExpr{column: column, op: " IN ", value: b, wrap: true}
+		}
+	}
+	return &inExpr{column: column, op: " IN (", values: values}
+}
+
+// NotIn creates a column NOT IN (values...) condition. A single Builder value
+// is rendered as a subquery.
+func NotIn(column string, values ...interface{}) Builder {
+	if len(values) == 1 {
+		if b, ok := values[0].(Builder); ok {
+			return &compareExpr{column: column, op: " NOT IN ", value: b, wrap: true}
+		}
+	}
+	return &inExpr{column: column, op: " NOT IN (", values: values}
+}
+
+type inExpr struct {
+	column string
+	op     string
+	values []interface{}
+}
+
+func (e *inExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString(e.column)
+	buf.WriteString(e.op)
+	writePlaceholders(buf, len(e.Params()))
+	buf.WriteString(")")
+	return nil
+}
+
+func (e *inExpr) Params() []interface{} {
+	return expandParams(e.values)
+}
+
+// IsNull creates a column IS NULL condition
+func IsNull(column string) Builder {
+	return &rawExpr{query: column + " IS NULL"}
+}
+
+// IsNotNull creates a column IS NOT NULL condition
+func IsNotNull(column string) Builder {
+	return &rawExpr{query: column + " IS NOT NULL"}
+}
+
+// Exists creates an EXISTS (subquery) condition
+func Exists(subquery Builder) Builder {
+	return &existsExpr{op: "EXISTS ", subquery: subquery}
+}
+
+// NotExists creates a NOT EXISTS (subquery) condition
+func NotExists(subquery Builder) Builder {
+	return &existsExpr{op: "NOT EXISTS ", subquery: subquery}
+}
+
+type existsExpr struct {
+	op       string
+	subquery Builder
+}
+
+func (e *existsExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString(e.op)
+	return writeSubquery(buf, e.subquery)
+}
+
+func (e *existsExpr) Params() []interface{} {
+	return e.subquery.Params()
+}
+
+// As aliases a column, table or subquery. The expression is either a raw SQL
+// string or a Builder; a *SelectQuery is rendered as a parenthesised subquery.
+func As(expr interface{}, alias string) Builder {
+	return &aliasExpr{expr: toExpr(expr), alias: alias}
+}
+
+type aliasExpr struct {
+	expr  Builder
+	alias string
+}
+
+func (a *aliasExpr) Build(buf *bytes.Buffer) error {
+	if err := writeExpr(buf, a.expr); err != nil {
+		return err
+	}
+	buf.WriteString(" AS ")
+	buf.WriteString(a.alias)
+	return nil
+}
+
+func (a *aliasExpr) Params() []interface{} {
+	return a.expr.Params()
+}
+
+// tableExpr converts a table name or a Builder with an optional alias into a
+// Builder that renders a table reference
+func tableExpr(table interface{}, alias []string) Builder {
+	if name, ok := table.(string); ok {
+		return Expr(strings.Join(append([]string{name}, alias...), " "))
+	} else if len(alias) > 0 {
+		return As(table, alias[0])
+	}
+	return &subqueryExpr{toExpr(table)}
+}
+
+// subqueryExpr renders a Builder, wrapping it in parentheses if it is a subquery
+type subqueryExpr struct {
+	builder Builder
+}
+
+func (s *subqueryExpr) Build(buf *bytes.Buffer) error {
+	return writeExpr(buf, s.builder)
+}
+
+func (s *subqueryExpr) Params() []interface{} {
+	return s.builder.Params()
+}
+
+// writeExpr renders b, wrapping it in parentheses if it is a subquery
+func writeExpr(buf *bytes.Buffer, b Builder) error {
+	if _, ok := b.(*SelectQuery); ok {
+		return writeSubquery(buf, b)
+	}
+	return b.Build(buf)
+}
+
+// writeSubquery renders b wrapped in parentheses
+func writeSubquery(buf *bytes.Buffer, b Builder) error {
+	buf.WriteString("(")
+	if err := b.Build(buf); err != nil {
+		return err
+	}
+	buf.WriteString(")")
+	return nil
+}
+
+// sliceParam reports whether param is a slice or array that should be
+// expanded into one placeholder per element. Byte slices and driver.Valuer
+// implementations are passed to the driver as a single value.
+func sliceParam(param interface{}) (reflect.Value, bool) {
+	if param == nil {
+		return reflect.Value{}, false
+	}
+	if _, ok := param.(driver.Valuer); ok {
+		return reflect.Value{}, false
+	}
+	v := reflect.ValueOf(param)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return reflect.Value{}, false
+		}
+		return v, true
+	case reflect.Array:
+		return v, true
+	}
+	return reflect.Value{}, false
+}
+
+// hasExpandableParam reports whether any of the params is a slice or a Builder
+func hasExpandableParam(params []interface{}) bool {
+	for _, param := range params {
+		if _, ok := param.(Builder); ok {
+			return true
+		}
+		if _, ok := sliceParam(param); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandParams flattens slice params into their individual elements and
+// replaces Builder params with their own params
+func expandParams(params []interface{}) []interface{} {
+	if !hasExpandableParam(params) {
+		return params
+	}
+	p := make([]interface{}, 0, len(params))
+	for _, param := range params {
+		if b, ok := param.(Builder); ok {
+			p = append(p, b.Params()...)
+			continue
+		}
+		if slice, ok := sliceParam(param); ok {
+			for i := 0; i < slice.Len(); i++ {
+				p = append(p, slice.Index(i).Interface())
+			}
+			continue
+		}
+		p = append(p, param)
+	}
+	return p
+}
+
+// writePlaceholders writes n comma separated placeholders. An empty list is
+// valid in SQLite where x IN () is always false and x NOT IN () always true.
+func writePlaceholders(buf *bytes.Buffer, n int) {
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("?")
+	}
+}
+
+// toExpr converts a condition given as a raw string or a Builder into a Builder
+func toExpr(condition interface{}, params ...interface{}) Builder {
+	switch c := condition.(type) {
+	case string:
+		return &rawExpr{query: c, params: params}
+	case Builder:
+		return c
+	}
+	return &invalidExpr{condition}
+}
+
+type invalidExpr struct {
+	value interface{}
+}
+
+func (e *invalidExpr) Build(buf *bytes.Buffer) error {
+	return &BuildError{Reason: fmt.Sprintf("unsupported expression of type %T", e.value)}
+}
+
+func (e *invalidExpr) Params() []interface{} {
+	return nil
+}
+
+// writeJoined renders the builders separated by sep. When combined using AND,
+// operands containing a top-level OR are parenthesised to keep their meaning.
+func writeJoined(buf *bytes.Buffer, builders []Builder, sep string) error {
+	for i, b := range builders {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		if len(builders) > 1 && sep == " AND " && hasTopLevelOr(b) {
+			if err := writeSubquery(buf, b); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := b.Build(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasTopLevelOr reports whether b renders an expression with an OR operator
+// outside of parentheses, which binds looser than AND
+func hasTopLevelOr(b Builder) bool {
+	switch e := b.(type) {
+	case *rawExpr:
+		return containsTopLevelOr(e.query)
+	case *junction:
+		return len(e.conditions) == 1 && hasTopLevelOr(e.conditions[0])
+	}
+	return false
+}
+
+// containsTopLevelOr reports whether query contains the OR keyword outside of
+// parentheses, quoted strings and identifiers
+func containsTopLevelOr(query string) bool {
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		if end := skipLiteral(query, i); end > i {
+			i = end - 1
+			continue
+		}
+		c := query[i]
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == 'o' || c == 'O') && i+1 < len(query) && (query[i+1] == 'r' || query[i+1] == 'R'):
+			if (i == 0 || !isParamNameChar(query[i-1])) && (i+2 == len(query) || !isParamNameChar(query[i+2])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func joinedParams(builders []Builder) []interface{} {
+	var p []interface{}
+	for _, b := range builders {
+		p = append(p, b.Params()...)
+	}
+	return p
+}
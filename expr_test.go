@@ -0,0 +1,233 @@
+package qb
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpressions(t *testing.T) {
+	type test struct {
+		name   string
+		expr   Builder
+		result string
+		values []interface{}
+	}
+
+	var testResults = []test{
+		{
+			name:   "raw expression",
+			expr:   Expr("a = ? OR b = ?", 1, 2),
+			result: "a = ? OR b = ?",
+			values: []interface{}{1, 2},
+		},
+		{
+			name:   "raw expression with slice",
+			expr:   Expr("a = ? AND b IN (?) AND c = ?", 1, []int64{2, 3, 4}, "x"),
+			result: "a = ? AND b IN (?, ?, ?) AND c = ?",
+			values: []interface{}{1, int64(2), int64(3), int64(4), "x"},
+		},
+		{
+			name:   "raw expression with numbered params",
+			expr:   Expr("a = ?1 OR b = ?1", 1),
+			result: "a = ?1 OR b = ?1",
+			values: []interface{}{1},
+		},
+		{
+			name:   "raw expression with line comment",
+			expr:   Expr("a = ? -- why?", 1),
+			result: "a = ? -- why?",
+			values: []interface{}{1},
+		},
+		{
+			name:   "raw expression with block comment",
+			expr:   Expr("a = ? /* ok? */", 1),
+			result: "a = ? /* ok? */",
+			values: []interface{}{1},
+		},
+		{
+			name:   "raw expression with bracketed identifier",
+			expr:   Expr("[col?] = ?", 1),
+			result: "[col?] = ?",
+			values: []interface{}{1},
+		},
+		{
+			name:   "raw expression with slice and comment",
+			expr:   Expr("a IN (?) /* b = ? */", []int{1, 2}),
+			result: "a IN (?, ?) /* b = ? */",
+			values: []interface{}{1, 2},
+		},
+		{
+			name:   "raw expression with empty slice",
+			expr:   Expr("b IN (?)", []string{}),
+			result: "b IN ()",
+			values: []interface{}{},
+		},
+		{
+			name:   "raw expression with byte slice",
+			expr:   Expr("data = ? AND name = '?'", []byte("fuu")),
+			result: "data = ? AND name = '?'",
+			values: []interface{}{[]byte("fuu")},
+		},
+		{
+			name:   "raw expression with quoted placeholder and slice",
+			expr:   Expr("name = '?' AND id IN (?)", []int{1, 2}),
+			result: "name = '?' AND id IN (?, ?)",
+			values: []interface{}{1, 2},
+		},
+		{
+			name:   "equals",
+			expr:   Eq("a", 1),
+			result: "a = ?",
+			values: []interface{}{1},
+		},
+		{
+			name:   "equals nil",
+			expr:   Eq("a", nil),
+			result: "a IS NULL",
+		},
+		{
+			name:   "not equals nil",
+			expr:   Neq("a", nil),
+			result: "a IS NOT NULL",
+		},
+		{
+			name:   "like",
+			expr:   Like("name", "fuu%"),
+			result: "name LIKE ?",
+			values: []interface{}{"fuu%"},
+		},
+		{
+			name:   "in",
+			expr:   In("id", 1, 2, 3),
+			result: "id IN (?, ?, ?)",
+			values: []interface{}{1, 2, 3},
+		},
+		{
+			name:   "in with slice",
+			expr:   In("id", []int{1, 2}),
+			result: "id IN (?, ?)",
+			values: []interface{}{1, 2},
+		},
+		{
+			name:   "in with subquery",
+			expr:   In("id", (&SelectQuery{table: "bar"}).Columns("fuu_id").Where("type = ?", "a")),
+			result: "id IN (SELECT fuu_id FROM bar WHERE type = ?)",
+			values: []interface{}{"a"},
+		},
+		{
+			name:   "equals subquery",
+			expr:   Eq("total", (&SelectQuery{table: "bar"}).Columns("MAX(total)")),
+			result: "total = (SELECT MAX(total) FROM bar)",
+		},
+		{
+			name:   "not exists",
+			expr:   NotExists((&SelectQuery{table: "bar"}).Where("bar.id = ?", 1)),
+			result: "NOT EXISTS (SELECT * FROM bar WHERE bar.id = ?)",
+			values: []interface{}{1},
+		},
+		{
+			name:   "alias expression",
+			expr:   As("COUNT(*)", "cnt"),
+			result: "COUNT(*) AS cnt",
+		},
+		{
+			name:   "not in",
+			expr:   NotIn("id", 1),
+			result: "id NOT IN (?)",
+			values: []interface{}{1},
+		},
+		{
+			name:   "not single condition",
+			expr:   Not(Eq("a", 1)),
+			result: "NOT (a = ?)",
+			values: []interface{}{1},
+		},
+		{
+			name:   "empty and",
+			expr:   And(),
+			result: "1",
+		},
+		{
+			name:   "empty or",
+			expr:   Or(),
+			result: "0",
+		},
+		{
+			name:   "single condition is not parenthesised",
+			expr:   Or(Eq("a", 1)),
+			result: "a = ?",
+			values: []interface{}{1},
+		},
+		{
+			name:   "nested groups",
+			expr:   Or(Eq("a", 1), And(Gt("b", 2), IsNull("c"))),
+			result: "(a = ? OR (b > ? AND c IS NULL))",
+			values: []interface{}{1, 2},
+		},
+		{
+			name:   "raw operand with or",
+			expr:   And(Expr("a = 1 OR b = 2"), Eq("c", 3)),
+			result: "((a = 1 OR b = 2) AND c = ?)",
+			values: []interface{}{3},
+		},
+		{
+			name:   "raw operand with or in parentheses or quotes",
+			expr:   And(Expr("(a = 1 OR b = 2)"), Expr("name = 'this or that'"), Expr("color = ?", "orange")),
+			result: "((a = 1 OR b = 2) AND name = 'this or that' AND color = ?)",
+			values: []interface{}{"orange"},
+		},
+		{
+			name:   "single or operand",
+			expr:   And(Or(Expr("a = 1 OR b = 2")), IsNull("c")),
+			result: "((a = 1 OR b = 2) AND c IS NULL)",
+		},
+		{
+			name:   "not",
+			expr:   Not(Or(Lt("a", 1), Gte("a", 10))),
+			result: "NOT (a < ? OR a >= ?)",
+			values: []interface{}{1, 10},
+		},
+	}
+
+	for _, tst := range testResults {
+		t.Run(tst.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+
+			if err := tst.expr.Build(&buf); err != nil {
+				t.Fatal(err)
+			} else if buf.String() != tst.result {
+				t.Fatalf("got: %s -- expected: %s", buf.String(), tst.result)
+			} else if !reflect.DeepEqual(tst.expr.Params(), tst.values) {
+				t.Fatalf("got: %v -- expected: %v", tst.expr.Params(), tst.values)
+			}
+		})
+	}
+}
+
+func TestInvalidExpression(t *testing.T) {
+	query := &SelectQuery{table: "fuu"}
+	query.Where(123)
+
+	if err := query.Build(&bytes.Buffer{}); err == nil {
+		t.Fatal("Expected an error for an unsupported condition type")
+	}
+}
+
+func TestBuildError(t *testing.T) {
+	query := (&UpdateQuery{table: "fuu"}).Set("bar", 1).Where("id = ? AND baz = ?", 1)
+
+	expected := `qb: UPDATE: WHERE: expression "id = ? AND baz = ?" has 2 placeholders but 1 params`
+	if err := query.Build(&bytes.Buffer{}); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("got: %v -- expected: %s", err, ErrInvalidQuery)
+	} else if err.Error() != expected {
+		t.Fatalf("got: %s -- expected: %s", err, expected)
+	}
+
+	subquery := (&SelectQuery{}).Columns("id")
+	expected = "qb: SELECT: FROM: no table to select from"
+	if err := (&DeleteQuery{table: "fuu"}).Where(In("id", subquery)).Build(&bytes.Buffer{}); err == nil || err.Error() != expected {
+		t.Fatalf("got: %v -- expected: %s", err, expected)
+	}
+}
@@ -2,7 +2,10 @@ package qb
 
 import (
 	"context"
+	"database/sql"
+	"reflect"
 	"testing"
+	"time"
 )
 
 const fuuSchema = `PRAGMA foreign_keys = ON;
@@ -56,3 +59,143 @@ func TestForeignKeys(t *testing.T) {
 		t.Fatalf("Expected 3 record but got %d", totalCount)
 	}
 }
+
+func TestJoinForeignKeys(t *testing.T) {
+	db := createTestDB(t, fuuSchema, "")
+	defer db.Close()
+
+	ctx := context.Background()
+
+	names := []string{}
+	q := db.Select().From("track t").Columns("t.name").
+		InnerJoin(As("artist", "a"), Eq("a.id", Expr("t.artist"))).
+		Where("a.name = ?", "Dean Martin").
+		OrderBy("t.id", "ASC")
+	if _, err := db.Load(ctx, q, &names); err != nil {
+		t.Fatal(err)
+	} else if len(names) != 2 || names[0] != "That is Amore" {
+		t.Fatalf("Expected the tracks of Dean Martin but got %v", names)
+	}
+}
+
+func TestJoinPrefixedStructs(t *testing.T) {
+	db := createTestDB(t, fuuSchema, "INSERT INTO track VALUES (14, 'Unknown', NULL)")
+	defer db.Close()
+
+	ctx := context.Background()
+
+	type artist struct {
+		ID   int64
+		Name string
+	}
+	type track struct {
+		ID       int64
+		Name     string
+		ArtistID sql.NullInt64 `db:"artist"`
+		Artist   *artist       `db:"artist,prefix=artist__"`
+	}
+
+	tracks := []track{}
+	q := db.Select().From("track t").Columns("t.*").ColumnExprs(As("a.id", "artist__id"), As("a.name", "artist__name")).
+		LeftJoin(As("artist", "a"), Eq("a.id", Expr("t.artist"))).
+		OrderBy("t.id", "ASC")
+	if _, err := db.Load(WithStrict(ctx, StrictColumns), q, &tracks); err != nil {
+		t.Fatal(err)
+	} else if len(tracks) != 4 {
+		t.Fatalf("Expected 4 tracks but got %d", len(tracks))
+	} else if tracks[0].ID != 11 || tracks[0].Artist == nil || tracks[0].Artist.ID != 1 || tracks[0].Artist.Name != "Dean Martin" {
+		t.Fatalf("Expected track 11 by Dean Martin but got %v", tracks[0])
+	} else if tracks[3].ID != 14 || tracks[3].Artist != nil {
+		t.Fatalf("Expected track 14 without an artist but got %v", tracks[3])
+	}
+
+	type album struct {
+		Title  string
+		Artist artist `db:"artist,prefix"`
+	}
+
+	a := album{}
+	if _, err := db.Load(ctx, db.Select().From("artist").Columns("'Greatest Hits' AS title", `id AS "artist.id"`, `name AS "artist.name"`).Where("id = ?", 2), &a); err != nil {
+		t.Fatal(err)
+	} else if a.Title != "Greatest Hits" || a.Artist.ID != 2 || a.Artist.Name != "Frank Sinatra" {
+		t.Fatalf("Expected an album by Frank Sinatra but got %v", a)
+	}
+
+	insert := db.Insert().InTo("track").Record(track{ID: 15, Name: "New", Artist: &artist{ID: 1}})
+	if columns := insert.columns; !reflect.DeepEqual(columns, []string{"id", "name", "artist"}) {
+		t.Fatalf("Expected the columns of the prefixed struct to be skipped but got %v", columns)
+	}
+}
+
+func TestPreloadRelations(t *testing.T) {
+	db := createTestDB(t, fuuSchema, "INSERT INTO artist VALUES (3, 'Nat King Cole'); CREATE TABLE tag(artist TEXT, label TEXT);")
+	defer db.Close()
+
+	type artist struct {
+		ID   int64
+		Name string
+	}
+	type track struct {
+		ID     int64
+		Name   string
+		Artist int
+		Author *artist `qb:"belongsTo,fk=artist"`
+	}
+	type artistWithTracks struct {
+		ID     int64 `db:"id,pk"`
+		Name   string
+		Tracks []track `qb:"hasMany,table=track,fk=artist"`
+		First  *track  `qb:"hasOne,table=track,fk=artist"`
+	}
+
+	queries := 0
+	ctx := WithLogger(context.Background(), func(ctx context.Context, duration time.Duration, format string, v ...interface{}) {
+		queries++
+	})
+
+	artists := []artistWithTracks{}
+	q := db.Select().From("artist").OrderBy("id", "ASC").Preload("Tracks.Author", "First")
+	if _, err := db.Load(ctx, q, &artists); err != nil {
+		t.Fatal(err)
+	} else if queries != 4 {
+		t.Fatalf("Expected 4 queries but got %d", queries)
+	} else if len(artists) != 3 || len(artists[0].Tracks) != 2 || len(artists[1].Tracks) != 1 || len(artists[2].Tracks) != 0 {
+		t.Fatalf("Expected the tracks of each artist but got %v", artists)
+	} else if artists[1].Tracks[0].Name != "My Way" || artists[1].Tracks[0].Author.Name != "Frank Sinatra" {
+		t.Fatalf("Expected My Way by Frank Sinatra but got %v", artists[1].Tracks[0])
+	} else if artists[0].First == nil || artists[0].First.ID != 11 || artists[2].First != nil {
+		t.Fatalf("Expected the first track of each artist but got %v", artists)
+	}
+
+	tr, err := One[track](ctx, db, db.Select().From("track").Where("id = ?", 13).Preload("Author"))
+	if err != nil {
+		t.Fatal(err)
+	} else if tr.Author == nil || tr.Author.ID != 2 {
+		t.Fatalf("Expected track 13 by artist 2 but got %v", tr)
+	}
+
+	if _, err := All[track](ctx, db, db.Select().From("track").Preload("Album")); err == nil {
+		t.Fatal("Expected an error for an unknown relation")
+	}
+
+	type tag struct {
+		Artist string
+		Label  string
+	}
+	type taggedArtist struct {
+		ID   interface{} `db:"id,pk"`
+		Tags []tag       `qb:"hasMany,table=tag,fk=artist"`
+	}
+
+	if _, err := db.Exec(ctx, db.Insert().InTo("tag").Columns("artist", "label").Values("1", "crooner")); err != nil {
+		t.Fatal(err)
+	}
+
+	tagged := []taggedArtist{}
+	q = db.Select().From("artist").Columns("CAST(id AS BLOB) AS id").Where("id = ?", 1).Preload("Tags")
+	if _, err := db.Load(ctx, q, &tagged); err != nil {
+		t.Fatal(err)
+	} else if len(tagged) != 1 || len(tagged[0].Tags) != 1 || tagged[0].Tags[0].Label != "crooner" {
+		t.Fatalf("Expected the tags of an artist keyed by a blob but got %v", tagged)
+	}
+}
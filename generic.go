@@ -0,0 +1,116 @@
+package qb
+
+import (
+	"context"
+	"database/sql"
+	"iter"
+	"reflect"
+)
+
+// Loader is implemented by DB and Tx to execute read queries
+type Loader interface {
+	Load(ctx context.Context, b Builder, dest interface{}) (int, error)
+}
+
+// Querier is implemented by DB and Tx to execute read queries returning the
+// raw rows
+type Querier interface {
+	Rows(ctx context.Context, b Builder) (*sql.Rows, error)
+}
+
+// All executes a read query and returns all rows scanned into a slice of T
+func All[T any](ctx context.Context, l Loader, b Builder) ([]T, error) {
+	var dest []T
+	if _, err := l.Load(ctx, b, &dest); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// One executes a read query and returns the first row scanned into T, or
+// ErrNoRows if the query returned no rows
+func One[T any](ctx context.Context, l Loader, b Builder) (T, error) {
+	var dest T
+	rows, err := l.Load(ctx, b, &dest)
+	if err != nil {
+		return dest, err
+	}
+	if rows == 0 {
+		return dest, ErrNoRows
+	}
+	return dest, nil
+}
+
+// Value executes a read query that selects a single column and returns the
+// value of the first row, or ErrNoRows if the query returned no rows
+func Value[T any](ctx context.Context, l Loader, b Builder) (T, error) {
+	return One[T](ctx, l, b)
+}
+
+// Column executes a read query that selects a single column and returns the
+// values of all rows
+func Column[T any](ctx context.Context, l Loader, b Builder) ([]T, error) {
+	return All[T](ctx, l, b)
+}
+
+// Iterate executes a read query and returns an iterator over the rows scanned
+// into T one by one, for result sets too large to hold in memory. The rows are
+// closed when the loop ends, also when it ends early. Iteration stops with the
+// context's error when it is cancelled.
+func Iterate[T any](ctx context.Context, q Querier, b Builder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		rows, err := q.Rows(ctx, b)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		scanner := newRowScanner(columns, reflect.TypeFor[T]())
+		if err := scanner.check(strictModeOf(ctx, q, b)); err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			var value T
+			if err := scanner.scan(rows, reflect.ValueOf(&value).Elem()); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(value, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, mapError(err))
+		}
+	}
+}
+
+// Each executes a read query and calls fn for every row scanned into T. It
+// stops at the first error returned by fn.
+func Each[T any](ctx context.Context, q Querier, b Builder, fn func(*T) error) error {
+	for value, err := range Iterate[T](ctx, q, b) {
+		if err != nil {
+			return err
+		}
+		if err := fn(&value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
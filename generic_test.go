@@ -0,0 +1,119 @@
+package qb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenericLoad(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+
+	notes, err := All[note](ctx, db, db.Select().From("notes").OrderBy("id", "ASC"))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(notes) != 2 || notes[1].Name != "Test" {
+		t.Fatalf("Expected 2 notes but got %v", notes)
+	}
+
+	n, err := One[*note](ctx, db, db.Select().From("notes").Where("id = ?", 2))
+	if err != nil {
+		t.Fatal(err)
+	} else if n.Name != "Test" {
+		t.Fatalf("Expected Test but got %s", n.Name)
+	}
+
+	if _, err := One[note](ctx, db, db.Select().From("notes").Where("id = ?", 3)); err != ErrNoRows {
+		t.Fatalf("Expected ErrNoRows but got %v", err)
+	}
+
+	count, err := Value[int](ctx, db, db.Select().From("notes").Columns("COUNT(id)"))
+	if err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Fatalf("Expected 2 but got %d", count)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	names, err := Column[string](ctx, tx, tx.Select().From("notes").Columns("name").OrderBy("name", "ASC"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(names, []string{"Fuu", "Test"}) {
+		t.Fatalf("Expected [Fuu Test] but got %v", names)
+	}
+}
+
+func TestIterate(t *testing.T) {
+	db := createTestDB(t, notesSchema, `INSERT INTO notes (id, name, content) VALUES
+	(1, "Fuu", "This is bar"),
+	(2, "Test", "This is fuu"),
+	(3, "Bar", "This is test")`)
+	defer db.Close()
+
+	ctx := context.TODO()
+	q := db.Select().From("notes").OrderBy("id", "ASC")
+
+	ids := []int64{}
+	for n, err := range Iterate[*note](ctx, db, q) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, n.ID)
+		if n.ID == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Fatalf("Expected [1 2] but got %v", ids)
+	}
+
+	// The connection is released after breaking out of the loop
+	if count, err := Value[int](ctx, db, db.Select().From("notes").Columns("COUNT(id)")); err != nil {
+		t.Fatal(err)
+	} else if count != 3 {
+		t.Fatalf("Expected 3 but got %d", count)
+	}
+
+	names := []string{}
+	if err := Each(ctx, db, q, func(n *note) error {
+		names = append(names, n.Name)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(names, []string{"Fuu", "Test", "Bar"}) {
+		t.Fatalf("Expected [Fuu Test Bar] but got %v", names)
+	}
+
+	stop := errors.New("stop")
+	if err := Each(ctx, db, q, func(n *note) error { return stop }); err != stop {
+		t.Fatalf("Expected the callback error but got %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	defer cancel()
+	count := 0
+	for _, err := range Iterate[note](cancelled, db, q) {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("Expected context.Canceled but got %v", err)
+			}
+			break
+		}
+		count++
+		cancel()
+	}
+	if count != 1 {
+		t.Fatalf("Expected iteration to stop after 1 row but got %d", count)
+	}
+}
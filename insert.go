@@ -2,27 +2,53 @@ package qb
 
 import (
 	"bytes"
+	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 )
 
 // InsertQuery represents a INSERT sql query
 type InsertQuery struct {
-	orIgnore       bool
-	table          string
-	columns        []string
-	values         []interface{}
-	conflictColumn string
-	conflictSets   string
-	returning      []string
+	withClause
+	or            ConflictResolution
+	table         string
+	columns       []string
+	recordColumns bool
+	keys          []string
+	values        [][]interface{}
+	source        *SelectQuery
+	defaults      bool
+	conflicts     []*ConflictClause
+	returning     []string
+}
+
+// Or sets the conflict resolution algorithm, as in INSERT OR REPLACE INTO
+func (q *InsertQuery) Or(resolution ConflictResolution) *InsertQuery {
+	q.or = resolution
+	return q
 }
 
 // OrIgnore make the query behave using INSERT OR IGNORE INTO
 func (q *InsertQuery) OrIgnore() *InsertQuery {
-	q.orIgnore = true
-	return q
+	return q.Or(ConflictIgnore)
 }
 
+// OrReplace make the query behave using INSERT OR REPLACE INTO
+func (q *InsertQuery) OrReplace() *InsertQuery {
+	return q.Or(ConflictReplace)
+}
+
+// Replace make the query behave using the REPLACE INTO shorthand. Like Or
+// it replaces any previously set conflict resolution algorithm.
+func (q *InsertQuery) Replace() *InsertQuery {
+	return q.Or(replaceShorthand)
+}
+
+// replaceShorthand is the conflict resolution set by Replace, which behaves
+// like ConflictReplace but renders as REPLACE INTO
+const replaceShorthand ConflictResolution = "REPLACE INTO"
+
 // InTo is used to set the table to insert into
 func (q *InsertQuery) InTo(table string) *InsertQuery {
 	q.table = table
@@ -32,19 +58,41 @@ func (q *InsertQuery) InTo(table string) *InsertQuery {
 // Columns determines the columns to insert
 func (q *InsertQuery) Columns(columns ...string) *InsertQuery {
 	q.columns = columns
+	q.recordColumns = false
 	return q
 }
 
-// Values determines the values to insert
+// Values adds a row of values to insert. Call it multiple times to insert
+// multiple rows using a single query
 func (q *InsertQuery) Values(values ...interface{}) *InsertQuery {
-	q.values = values
+	q.values = append(q.values, values)
+	return q
+}
+
+// FromSelect inserts the rows returned by the given SELECT query instead of Values
+func (q *InsertQuery) FromSelect(query *SelectQuery) *InsertQuery {
+	q.source = query
 	return q
 }
 
-// OnConflict specifies what to do if there is a conflict
+// DefaultValues inserts a single row consisting of only default values
+func (q *InsertQuery) DefaultValues() *InsertQuery {
+	q.defaults = true
+	return q
+}
+
+// OnConflict specifies what to do if there is a conflict on column using a raw
+// DO UPDATE SET clause. Use Upsert for more elaborate conflict handling.
 func (q *InsertQuery) OnConflict(column string, sets string) *InsertQuery {
-	q.conflictColumn = column
-	q.conflictSets = sets
+	c := Conflict(column)
+	c.sets = append(c.sets, setClause{value: Expr(sets)})
+	return q.Upsert(c)
+}
+
+// Upsert adds an ON CONFLICT clause. Multiple clauses are evaluated in order,
+// in which case all but the last one require a conflict target.
+func (q *InsertQuery) Upsert(conflict *ConflictClause) *InsertQuery {
+	q.conflicts = append(q.conflicts, conflict)
 	return q
 }
 
@@ -54,15 +102,39 @@ func (q *InsertQuery) Returning(returning ...string) *InsertQuery {
 	return q
 }
 
-// Record populates Values from the struct fields matching Columns
+// Records adds a row for every struct in the given slice using Record
+func (q *InsertQuery) Records(structValues interface{}) *InsertQuery {
+	value := reflect.Indirect(reflect.ValueOf(structValues))
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return q
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		q.Record(value.Index(i).Interface())
+	}
+
+	return q
+}
+
+// Record adds a row of values from the struct fields matching Columns. If no
+// Columns are set they are derived from the struct's db tags, see
+// UpdateQuery.SetRecord for the supported tag options. Records added this way
+// share the columns written for any of them: a zero valued field tagged
+// autoincrement that one record skips is inserted as NULL, and one tagged
+// omitempty as its zero value.
 func (q *InsertQuery) Record(structValue interface{}) *InsertQuery {
 	value := reflect.Indirect(reflect.ValueOf(structValue))
 
 	if value.Kind() == reflect.Struct {
-		var values []interface{}
-		structFields := structMap(value)
+		info := getStructInfo(value.Type())
+		q.keys = info.keys
+		if len(q.columns) == 0 || q.recordColumns {
+			return q.addRecord(info, value)
+		}
+		values := make([]interface{}, 0, len(q.columns))
 		for _, column := range q.columns {
-			if val, ok := structFields[column]; ok {
+			if val, ok := info.fieldValue(value, column); ok {
 				values = append(values, val.Interface())
 			} else {
 				values = append(values, nil)
@@ -74,13 +146,102 @@ func (q *InsertQuery) Record(structValue interface{}) *InsertQuery {
 	return q
 }
 
+// addRecord adds a row for the struct value with the columns derived from its
+// db tags. Columns it writes that previous records skipped are added to the
+// rows of those records.
+func (q *InsertQuery) addRecord(info *structInfo, value reflect.Value) *InsertQuery {
+	columns, values := recordValues(value, false)
+	written := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		written[column] = values[i]
+	}
+
+	if merged := mergeColumns(info, q.columns, columns); len(merged) > len(q.columns) {
+		for i, row := range q.values {
+			remapped := make([]interface{}, len(merged))
+			for j, column := range merged {
+				if k := slices.Index(q.columns, column); k >= 0 && k < len(row) {
+					remapped[j] = row[k]
+				} else {
+					remapped[j] = skippedValue(info, value.Type(), column)
+				}
+			}
+			q.values[i] = remapped
+		}
+		q.columns = merged
+	}
+	q.recordColumns = true
+
+	row := make([]interface{}, len(q.columns))
+	for i, column := range q.columns {
+		if val, ok := written[column]; ok {
+			row[i] = val
+		} else {
+			row[i] = skippedValue(info, value.Type(), column)
+		}
+	}
+	return q.Values(row...)
+}
+
+// mergeColumns returns the union of columns and added in the order of the
+// struct fields, followed by any columns not belonging to the struct
+func mergeColumns(info *structInfo, columns []string, added []string) []string {
+	used := make(map[string]bool, len(columns)+len(added))
+	for _, column := range columns {
+		used[column] = true
+	}
+	for _, column := range added {
+		used[column] = true
+	}
+	if len(used) == len(columns) {
+		return columns
+	}
+	merged := make([]string, 0, len(used))
+	for _, field := range info.fields {
+		if field.leaf && used[field.name] {
+			merged = append(merged, field.name)
+			delete(used, field.name)
+		}
+	}
+	for _, column := range columns {
+		if used[column] {
+			merged = append(merged, column)
+		}
+	}
+	return merged
+}
+
+// skippedValue returns the value inserted for a column that a record did not
+// write: NULL for a field tagged autoincrement so a rowid is assigned, the zero
+// value of the field otherwise and NULL if the struct has no such field
+func skippedValue(info *structInfo, t reflect.Type, column string) interface{} {
+	i, ok := info.byName[column]
+	if !ok || info.fields[i].options.Contains("autoincrement") {
+		return nil
+	}
+	return reflect.Zero(t.FieldByIndex(info.fields[i].index).Type).Interface()
+}
+
 // Build renders the INSERT query as a string
 func (q *InsertQuery) Build(buf *bytes.Buffer) error {
-	if q.orIgnore {
-		buf.WriteString("INSERT OR IGNORE INTO ")
+	return annotate(q.build(buf), "INSERT", "")
+}
+
+func (q *InsertQuery) build(buf *bytes.Buffer) error {
+	if err := q.validate(); err != nil {
+		return err
+	}
+
+	if err := q.writeWith(buf); err != nil {
+		return err
+	}
+
+	if q.or == replaceShorthand {
+		buf.WriteString("REPLACE")
 	} else {
-		buf.WriteString("INSERT INTO ")
+		q.or.write(buf, "INSERT")
 	}
+	buf.WriteString(" INTO ")
 	buf.WriteString(q.table)
 	if len(q.columns) > 0 {
 		buf.WriteString(" (")
@@ -88,19 +249,30 @@ func (q *InsertQuery) Build(buf *bytes.Buffer) error {
 		buf.WriteString(")")
 	}
 
-	buf.WriteString(" VALUES (")
-	fuus := []string{} // TODO make this better
-	for range q.values {
-		fuus = append(fuus, "?")
+	switch {
+	case q.source != nil:
+		buf.WriteString(" ")
+		if err := q.selectSource().Build(buf); err != nil {
+			return err
+		}
+	case q.defaults:
+		buf.WriteString(" DEFAULT VALUES")
+	default:
+		buf.WriteString(" VALUES ")
+		for i, row := range q.values {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString("(")
+			writePlaceholders(buf, len(row))
+			buf.WriteString(")")
+		}
 	}
-	buf.WriteString(strings.Join(fuus, ", "))
-	buf.WriteString(")")
 
-	if q.conflictColumn != "" {
-		buf.WriteString(" ON CONFLICT (")
-		buf.WriteString(q.conflictColumn)
-		buf.WriteString(") DO UPDATE SET ")
-		buf.WriteString(q.conflictSets)
+	for _, conflict := range q.conflicts {
+		if err := conflict.build(buf, q.columns, q.keys); err != nil {
+			return annotate(err, "", "ON CONFLICT")
+		}
 	}
 
 	if len(q.returning) > 0 {
@@ -111,7 +283,129 @@ func (q *InsertQuery) Build(buf *bytes.Buffer) error {
 	return nil
 }
 
+// validate checks that the query has a table and values that match its columns
+func (q *InsertQuery) validate() error {
+	if q.table == "" {
+		return &BuildError{Clause: "INTO", Reason: "no table to insert into"}
+	}
+
+	switch {
+	case q.defaults:
+		if q.source != nil || len(q.values) > 0 {
+			return &BuildError{Clause: "DEFAULT VALUES", Reason: "cannot be combined with VALUES or SELECT"}
+		}
+		if len(q.columns) > 0 {
+			return &BuildError{Clause: "DEFAULT VALUES", Reason: "cannot be combined with columns"}
+		}
+		if len(q.conflicts) > 0 {
+			return &BuildError{Clause: "ON CONFLICT", Reason: "is not supported with DEFAULT VALUES"}
+		}
+	case q.source != nil:
+		if len(q.values) > 0 {
+			return &BuildError{Clause: "SELECT", Reason: "cannot be combined with VALUES"}
+		}
+	case len(q.values) == 0:
+		return &BuildError{Clause: "VALUES", Reason: "no values to insert"}
+	default:
+		width := len(q.columns)
+		if width == 0 {
+			width = len(q.values[0])
+		}
+		for i, row := range q.values {
+			if len(row) != width {
+				return &BuildError{Clause: "VALUES", Reason: fmt.Sprintf("row %d has %d values but expected %d", i+1, len(row), width)}
+			}
+		}
+	}
+	return nil
+}
+
+func (q *InsertQuery) hasReturning() bool {
+	return len(q.returning) > 0
+}
+
+// selectSource returns the SELECT query to insert from. In combination with
+// ON CONFLICT SQLite requires it to have a WHERE clause to avoid a parsing
+// ambiguity, so WHERE true is added if it has none. A compound SELECT is
+// wrapped in a subquery, as its last member would otherwise precede ON
+// CONFLICT.
+func (q *InsertQuery) selectSource() *SelectQuery {
+	if len(q.conflicts) == 0 {
+		return q.source
+	}
+	if len(q.source.compounds) > 0 {
+		return (&SelectQuery{}).From(q.source).Where("true")
+	}
+	if len(q.source.wheres) > 0 {
+		return q.source
+	}
+	source := *q.source
+	source.wheres = []Builder{Expr("true")}
+	return &source
+}
+
+// With adds a Common Table Expression to the beginning of the query. Wrap the
+// query in qb.Materialized or qb.NotMaterialized to add a hint.
+func (q *InsertQuery) With(name string, query Builder) *InsertQuery {
+	q.addWith(name, nil, query, false)
+	return q
+}
+
+// WithRecursive adds a recursive Common Table Expression with the given
+// columns to the beginning of the query
+func (q *InsertQuery) WithRecursive(name string, columns []string, query Builder) *InsertQuery {
+	q.addWith(name, columns, query, true)
+	return q
+}
+
 // Params returns all parameters for the query
 func (q *InsertQuery) Params() []interface{} {
-	return q.values
+	p := q.withParams()
+	switch {
+	case q.source != nil:
+		p = append(p, q.source.Params()...)
+	case q.defaults:
+	default:
+		for _, row := range q.values {
+			p = append(p, row...)
+		}
+	}
+	return append(p, q.conflictParams()...)
+}
+
+func (q *InsertQuery) conflictParams() []interface{} {
+	var p []interface{}
+	for _, conflict := range q.conflicts {
+		p = append(p, conflict.params(q.columns, q.keys)...)
+	}
+	return p
+}
+
+// batches splits a multi row insert into queries that each bind at most
+// limit parameters
+func (q *InsertQuery) batches(limit int) []Builder {
+	if q.source != nil || q.defaults || len(q.values) < 2 {
+		return []Builder{q}
+	}
+	size := limit - len(q.withParams()) - len(q.conflictParams())
+	if width := len(q.values[0]); width > 0 {
+		size /= width
+	}
+	if size < 1 {
+		size = 1
+	}
+	if len(q.values) <= size {
+		return []Builder{q}
+	}
+	var batches []Builder
+	for start := 0; start < len(q.values); start += size {
+		end := start + size
+		if end > len(q.values) {
+			end = len(q.values)
+		}
+		batch := *q
+		batch.values = q.values[start:end]
+		batches = append(batches, &batch)
+	}
+	return batches
 }
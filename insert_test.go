@@ -2,8 +2,10 @@ package qb
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestInsertQuery(t *testing.T) {
@@ -17,12 +19,41 @@ func TestInsertQuery(t *testing.T) {
 
 	var testResults = []test{
 		{
-			name: "insert nothing", // TODO consider throwing an error here
+			name: "insert nothing",
 			query: func() *InsertQuery {
 				query := &InsertQuery{table: "fuu"}
 				return query
 			},
-			result: "INSERT INTO fuu VALUES ()",
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert without table",
+			query: func() *InsertQuery {
+				query := &InsertQuery{}
+				query.Values(123)
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert fewer values than columns",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2", "column3")
+				query.Values(123, "fuubar")
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert rows of different length",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Values(123, "fuubar")
+				query.Values(456)
+				return query
+			},
+			err: ErrInvalidQuery,
 		},
 		{
 			name: "insert and returning",
@@ -46,6 +77,64 @@ func TestInsertQuery(t *testing.T) {
 			result: "INSERT OR IGNORE INTO fuu VALUES (?, ?)",
 			values: []interface{}{123, "fuubar"},
 		},
+		{
+			name: "insert or replace",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Or(ConflictReplace)
+				query.Values(123)
+				return query
+			},
+			result: "INSERT OR REPLACE INTO fuu VALUES (?)",
+			values: []interface{}{123},
+		},
+		{
+			name: "insert or rollback",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Or(ConflictRollback)
+				query.Values(123)
+				return query
+			},
+			result: "INSERT OR ROLLBACK INTO fuu VALUES (?)",
+			values: []interface{}{123},
+		},
+		{
+			name: "replace into",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Replace()
+				query.Columns("column1")
+				query.Values(123)
+				return query
+			},
+			result: "REPLACE INTO fuu (column1) VALUES (?)",
+			values: []interface{}{123},
+		},
+		{
+			name: "replace into after or",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.OrIgnore()
+				query.Replace()
+				query.Values(123)
+				return query
+			},
+			result: "REPLACE INTO fuu VALUES (?)",
+			values: []interface{}{123},
+		},
+		{
+			name: "insert or after replace into",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Replace()
+				query.OrIgnore()
+				query.Values(123)
+				return query
+			},
+			result: "INSERT OR IGNORE INTO fuu VALUES (?)",
+			values: []interface{}{123},
+		},
 		{
 			name: "insert one column",
 			query: func() *InsertQuery {
@@ -82,6 +171,197 @@ func TestInsertQuery(t *testing.T) {
 			result: "INSERT INTO fuu (column1) VALUES (?) ON CONFLICT (column1) DO UPDATE SET column1=excluded.column1 RETURNING column1, column2",
 			values: []interface{}{"value1"},
 		},
+		{
+			name: "insert with composite conflict target do nothing",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2")
+				query.Values("value1", "value2")
+				query.Upsert(Conflict("column1", "column2").DoNothing())
+				return query
+			},
+			result: "INSERT INTO fuu (column1, column2) VALUES (?, ?) ON CONFLICT (column1, column2) DO NOTHING",
+			values: []interface{}{"value1", "value2"},
+		},
+		{
+			name: "insert with conflict on partial index and filtered update",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2", "hits")
+				query.Values("value1", "value2", 1)
+				query.Upsert(Conflict("column1").
+					Where("deleted = ?", false).
+					SetExcluded("column2").
+					SetExpr("hits", "hits + ?", 1).
+					Set("updated", "now").
+					UpdateWhere("column2 != excluded.column2 OR hits < ?", 10).
+					UpdateWhere("locked = ?", false))
+				return query
+			},
+			result: "INSERT INTO fuu (column1, column2, hits) VALUES (?, ?, ?) ON CONFLICT (column1) WHERE deleted = ? DO UPDATE SET column2 = excluded.column2, hits = hits + ?, updated = ? WHERE (column2 != excluded.column2 OR hits < ?) AND locked = ?",
+			values: []interface{}{"value1", "value2", 1, false, 1, "now", 10, false},
+		},
+		{
+			name: "insert with multiple conflict clauses",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("id", "name", "content")
+				query.Values(1, "name", "content")
+				query.Upsert(Conflict("id").SetExcluded())
+				query.Upsert(Conflict().DoNothing())
+				return query
+			},
+			result: "INSERT INTO fuu (id, name, content) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, content = excluded.content ON CONFLICT DO NOTHING",
+			values: []interface{}{1, "name", "content"},
+		},
+		{
+			name: "insert with conflict update without target",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("id", "name")
+				query.Values(1, "name")
+				query.Upsert(Conflict().Set("name", "other"))
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert with conflict where without target",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("id", "name")
+				query.Values(1, "name")
+				query.Upsert(Conflict().Where("deleted = ?", false).DoNothing())
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert with conflict update excluded without target",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("id", "name")
+				query.Values(1, "name")
+				query.Upsert(Conflict().SetExcluded())
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert record with conflict excluding keys",
+			query: func() *InsertQuery {
+				record := struct {
+					ID   int64  `db:"id,pk"`
+					Name string `db:"name"`
+					Year int    `db:"year"`
+				}{ID: 1, Name: "fuubar", Year: 2020}
+				query := &InsertQuery{table: "fuu"}
+				query.Record(&record)
+				query.Upsert(Conflict("name").SetExcluded())
+				return query
+			},
+			result: "INSERT INTO fuu (id, name, year) VALUES (?, ?, ?) ON CONFLICT (name) DO UPDATE SET year = excluded.year",
+			values: []interface{}{int64(1), "fuubar", 2020},
+		},
+		{
+			name: "insert from select",
+			query: func() *InsertQuery {
+				source := &SelectQuery{table: "bar"}
+				source.Columns("column1", "column2")
+				source.Where("column3 = ?", 123)
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2")
+				query.FromSelect(source)
+				query.Returning("id")
+				return query
+			},
+			result: "INSERT INTO fuu (column1, column2) SELECT column1, column2 FROM bar WHERE column3 = ? RETURNING id",
+			values: []interface{}{123},
+		},
+		{
+			name: "insert from select with on conflict",
+			query: func() *InsertQuery {
+				source := &SelectQuery{table: "bar"}
+				source.Columns("column1", "column2")
+				source.Join("JOIN baz ON baz.id = bar.baz_id")
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2")
+				query.FromSelect(source)
+				query.Upsert(Conflict("column1").Set("column2", "new"))
+				return query
+			},
+			result: "INSERT INTO fuu (column1, column2) SELECT column1, column2 FROM bar JOIN baz ON baz.id = bar.baz_id WHERE true ON CONFLICT (column1) DO UPDATE SET column2 = ?",
+			values: []interface{}{"new"},
+		},
+		{
+			name: "insert with cte",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.With("src", Expr("SELECT ? AS column1", "value1"))
+				query.Columns("column1")
+				query.FromSelect((&SelectQuery{table: "src"}).Columns("column1"))
+				return query
+			},
+			result: "WITH src AS (SELECT ? AS column1) INSERT INTO fuu (column1) SELECT column1 FROM src",
+			values: []interface{}{"value1"},
+		},
+		{
+			name: "insert or ignore default values",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.OrIgnore()
+				query.DefaultValues()
+				query.Returning("id")
+				return query
+			},
+			result: "INSERT OR IGNORE INTO fuu DEFAULT VALUES RETURNING id",
+		},
+		{
+			name: "insert compound select with on conflict",
+			query: func() *InsertQuery {
+				source := &SelectQuery{table: "bar"}
+				source.Columns("column1")
+				source.Where("column2 = ?", 1)
+				source.Union((&SelectQuery{table: "baz"}).Columns("column1"))
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1")
+				query.FromSelect(source)
+				query.Upsert(Conflict("column1").DoNothing())
+				return query
+			},
+			result: "INSERT INTO fuu (column1) SELECT * FROM (SELECT column1 FROM bar WHERE column2 = ? UNION SELECT column1 FROM baz) WHERE true ON CONFLICT (column1) DO NOTHING",
+			values: []interface{}{1},
+		},
+		{
+			name: "insert default values with columns",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1")
+				query.DefaultValues()
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert default values with values",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Values(5, 6)
+				query.DefaultValues()
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "insert from select with values",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Values(5)
+				query.FromSelect((&SelectQuery{table: "bar"}).Columns("column1"))
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
 		{
 			name: "insert record",
 			query: func() *InsertQuery {
@@ -105,6 +385,112 @@ func TestInsertQuery(t *testing.T) {
 			result: "INSERT INTO fuu (id, name, fuu_bar, non_existent) VALUES (?, ?, ?, ?)",
 			values: []interface{}{int64(12345), "fuubar", "testtest", 0},
 		},
+		{
+			name: "insert record with inferred columns",
+			query: func() *InsertQuery {
+				record := struct {
+					ID        int64     `db:"id,pk,autoincrement"`
+					Name      string    `db:"name"`
+					Content   string    `db:"content,omitempty"`
+					CreatedAt time.Time `db:"created_at,readonly"`
+					Ignored   string    `db:"-"`
+					FuuBar    int
+				}{
+					Name:   "fuubar",
+					FuuBar: 3,
+				}
+				query := &InsertQuery{table: "fuu"}
+				query.Record(&record)
+				return query
+			},
+			result: "INSERT INTO fuu (name, fuu_bar) VALUES (?, ?)",
+			values: []interface{}{"fuubar", 3},
+		},
+		{
+			name: "insert record with inferred columns and explicit id",
+			query: func() *InsertQuery {
+				type base struct {
+					ID int64 `db:"id,pk,autoincrement"`
+				}
+				record := struct {
+					base
+					Name string `db:"name"`
+				}{
+					base: base{ID: 12},
+					Name: "fuubar",
+				}
+				query := &InsertQuery{table: "fuu"}
+				query.Record(record)
+				return query
+			},
+			result: "INSERT INTO fuu (id, name) VALUES (?, ?)",
+			values: []interface{}{int64(12), "fuubar"},
+		},
+		{
+			name: "insert multiple rows",
+			query: func() *InsertQuery {
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("column1", "column2")
+				query.Values(1, "a")
+				query.Values(2, "b")
+				return query
+			},
+			result: "INSERT INTO fuu (column1, column2) VALUES (?, ?), (?, ?)",
+			values: []interface{}{1, "a", 2, "b"},
+		},
+		{
+			name: "insert records",
+			query: func() *InsertQuery {
+				records := []struct {
+					ID   int64
+					Name string
+				}{
+					{ID: 1, Name: "fuu"},
+					{ID: 2, Name: "bar"},
+				}
+				query := &InsertQuery{table: "fuu"}
+				query.Columns("id", "name")
+				query.Records(records)
+				return query
+			},
+			result: "INSERT INTO fuu (id, name) VALUES (?, ?), (?, ?)",
+			values: []interface{}{int64(1), "fuu", int64(2), "bar"},
+		},
+		{
+			name: "insert records with skipped fields",
+			query: func() *InsertQuery {
+				records := []struct {
+					ID      int64 `db:"id,pk,autoincrement"`
+					Name    string
+					Content string `db:"content,omitempty"`
+				}{
+					{Name: "a"},
+					{ID: 7, Name: "b", Content: "keep me"},
+				}
+				query := &InsertQuery{table: "notes"}
+				query.Records(records)
+				return query
+			},
+			result: "INSERT INTO notes (id, name, content) VALUES (?, ?, ?), (?, ?, ?)",
+			values: []interface{}{nil, "a", "", int64(7), "b", "keep me"},
+		},
+		{
+			name: "insert chained records with skipped fields",
+			query: func() *InsertQuery {
+				type record struct {
+					ID     int64 `db:"id,pk,autoincrement"`
+					Name   string
+					Status string `db:"status,omitempty"`
+				}
+				query := &InsertQuery{table: "notes"}
+				query.Record(record{Name: "c"})
+				query.Record(record{ID: 3, Name: "d", Status: "x"})
+				query.Record(record{Name: "e"})
+				return query
+			},
+			result: "INSERT INTO notes (id, name, status) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?)",
+			values: []interface{}{nil, "c", "", int64(3), "d", "x", nil, "e", ""},
+		},
 	}
 
 	for _, tst := range testResults {
@@ -112,8 +498,10 @@ func TestInsertQuery(t *testing.T) {
 			query := tst.query()
 			buf := bytes.Buffer{}
 
-			if err := query.Build(&buf); err != tst.err {
-				t.Fatal(err)
+			if err := query.Build(&buf); !errors.Is(err, tst.err) {
+				t.Fatalf("got: %v -- expected: %v", err, tst.err)
+			} else if tst.err != nil {
+				return
 			} else if buf.String() != tst.result {
 				t.Fatalf("got: %s -- expected: %s", buf.String(), tst.result)
 			} else if !reflect.DeepEqual(query.Params(), tst.values) {
@@ -122,3 +510,34 @@ func TestInsertQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestInsertQueryBatches(t *testing.T) {
+	query := &InsertQuery{table: "fuu"}
+	query.Columns("column1", "column2")
+	for i := 0; i < 5; i++ {
+		query.Values(i, "value")
+	}
+
+	batches := query.batches(4)
+	if len(batches) != 3 {
+		t.Fatalf("Expected 3 batches but got %d", len(batches))
+	}
+
+	expected := []string{
+		"INSERT INTO fuu (column1, column2) VALUES (?, ?), (?, ?)",
+		"INSERT INTO fuu (column1, column2) VALUES (?, ?), (?, ?)",
+		"INSERT INTO fuu (column1, column2) VALUES (?, ?)",
+	}
+	for i, batch := range batches {
+		buf := bytes.Buffer{}
+		if err := batch.Build(&buf); err != nil {
+			t.Fatal(err)
+		} else if buf.String() != expected[i] {
+			t.Fatalf("got: %s -- expected: %s", buf.String(), expected[i])
+		}
+	}
+
+	if !reflect.DeepEqual(batches[2].Params(), []interface{}{4, "value"}) {
+		t.Fatalf("got: %v -- expected: [4 value]", batches[2].Params())
+	}
+}
@@ -0,0 +1,71 @@
+package qb
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Using creates a USING (columns...) join constraint to be used instead of an
+// ON condition
+func Using(columns ...string) Builder {
+	return &usingExpr{columns: columns}
+}
+
+type usingExpr struct {
+	columns []string
+}
+
+func (u *usingExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString("USING (")
+	buf.WriteString(strings.Join(u.columns, ", "))
+	buf.WriteString(")")
+	return nil
+}
+
+func (u *usingExpr) Params() []interface{} {
+	return nil
+}
+
+// joinExpr is a typed join of a SELECT query
+type joinExpr struct {
+	operator   string
+	table      Builder
+	constraint Builder
+	required   bool
+}
+
+func newJoin(operator string, table interface{}, condition interface{}, params []interface{}) *joinExpr {
+	j := &joinExpr{operator: operator, table: tableExpr(table, nil), required: true}
+	if condition != nil {
+		j.constraint = toExpr(condition, params...)
+	}
+	return j
+}
+
+func (j *joinExpr) Build(buf *bytes.Buffer) error {
+	if j.required && j.constraint == nil {
+		return &BuildError{Clause: j.operator, Reason: "requires an ON or USING condition"}
+	}
+	buf.WriteString(j.operator)
+	buf.WriteString(" ")
+	if err := j.table.Build(buf); err != nil {
+		return annotate(err, "", j.operator)
+	}
+	if j.constraint == nil {
+		return nil
+	}
+	if _, ok := j.constraint.(*usingExpr); ok {
+		buf.WriteString(" ")
+	} else {
+		buf.WriteString(" ON ")
+	}
+	return annotate(j.constraint.Build(buf), "", j.operator)
+}
+
+func (j *joinExpr) Params() []interface{} {
+	p := j.table.Params()
+	if j.constraint != nil {
+		p = append(p, j.constraint.Params()...)
+	}
+	return p
+}
@@ -0,0 +1,288 @@
+package qb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// relationKind is the kind of a relation between two structs
+type relationKind string
+
+const (
+	hasOne    relationKind = "hasOne"
+	hasMany   relationKind = "hasMany"
+	belongsTo relationKind = "belongsTo"
+)
+
+// relation describes a struct field declared as a relation with a qb struct
+// tag, as in `qb:"hasMany,table=track,fk=artist"`. For hasOne and hasMany the
+// fk column of table references the key column of the struct, which defaults
+// to its primary key or id. For belongsTo the fk column of the struct
+// references the key column of table, which defaults to id.
+type relation struct {
+	kind  relationKind
+	index int
+	elem  reflect.Type
+	table string
+	fk    string
+	key   string
+}
+
+// parseRelations returns the relations declared on the fields of t by name
+func parseRelations(t reflect.Type) map[string]*relation {
+	relations := make(map[string]*relation)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("qb")
+		if !ok || field.PkgPath != "" {
+			continue
+		}
+		kind, options := parseTag(tag)
+
+		elem := field.Type
+		if elem.Kind() == reflect.Slice {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+
+		rel := &relation{kind: relationKind(kind), index: i, elem: elem}
+		rel.table, _ = options.Lookup("table")
+		if rel.table == "" {
+			rel.table = camelCaseToSnakeCase(elem.Name())
+		}
+		rel.fk, _ = options.Lookup("fk")
+		rel.key, _ = options.Lookup("key")
+		relations[field.Name] = rel
+	}
+	return relations
+}
+
+// Preload loads the relations with the given field names of the structs
+// selected by the query, using one query per relation. Nested relations are
+// separated by dots, as in "Tracks.Album".
+func (q *SelectQuery) Preload(relations ...string) *SelectQuery {
+	q.preloads = append(q.preloads, relations...)
+	return q
+}
+
+// preload loads the relations of the structs loaded into dest
+func preload(ctx context.Context, r runner, dest interface{}, paths []string) error {
+	v := reflect.ValueOf(dest).Elem()
+
+	var parents []reflect.Value
+	switch v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if elem := reflect.Indirect(v.Index(i)); elem.IsValid() {
+				parents = append(parents, elem)
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			parents = append(parents, v.Elem())
+		}
+	default:
+		parents = append(parents, v)
+	}
+
+	t := v.Type()
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fmt.Errorf("qb: cannot preload relations into %T", dest)
+	}
+
+	return preloadRelations(ctx, r, t, parents, paths)
+}
+
+// preloadRelations loads the relations of parents, structs of type t
+func preloadRelations(ctx context.Context, r runner, t reflect.Type, parents []reflect.Value, paths []string) error {
+	var names []string
+	nested := make(map[string][]string)
+	for _, path := range paths {
+		name, rest, _ := strings.Cut(path, ".")
+		if _, ok := nested[name]; !ok {
+			names = append(names, name)
+			nested[name] = nil
+		}
+		if rest != "" {
+			nested[name] = append(nested[name], rest)
+		}
+	}
+
+	info := getStructInfo(t)
+	for _, name := range names {
+		rel, ok := info.relations[name]
+		if !ok {
+			return fmt.Errorf("qb: %s has no relation %s", t, name)
+		}
+		if err := rel.load(ctx, r, t, parents, nested[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load selects the related rows of all parents in a single query and assigns
+// them to the relation field of each parent
+func (rel *relation) load(ctx context.Context, r runner, t reflect.Type, parents []reflect.Value, nested []string) error {
+	if rel.fk == "" {
+		return fmt.Errorf("qb: relation %s of %s requires an fk", t.Field(rel.index).Name, t)
+	}
+
+	parentInfo, childInfo := getStructInfo(t), getStructInfo(rel.elem)
+
+	parentColumn, childColumn := rel.key, rel.fk
+	if rel.kind == belongsTo {
+		parentColumn, childColumn = rel.fk, rel.key
+		if childColumn == "" {
+			childColumn = defaultKey(childInfo)
+		}
+	} else if rel.kind == hasOne || rel.kind == hasMany {
+		if parentColumn == "" {
+			parentColumn = defaultKey(parentInfo)
+		}
+	} else {
+		return fmt.Errorf("qb: unknown relation kind %s", rel.kind)
+	}
+
+	if _, ok := parentInfo.byName[parentColumn]; !ok {
+		return fmt.Errorf("qb: %s has no column %s", t, parentColumn)
+	}
+	if _, ok := childInfo.byName[childColumn]; !ok {
+		return fmt.Errorf("qb: %s has no column %s", rel.elem, childColumn)
+	}
+
+	var keys []interface{}
+	seen := make(map[interface{}]bool)
+	parentKeys := make([]interface{}, len(parents))
+	for i, parent := range parents {
+		key, ok, err := relationKey(parentInfo, parent, parentColumn)
+		if err != nil {
+			return err
+		}
+		if ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+		parentKeys[i] = key
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// Select the children in batches to stay below maxVariables
+	children := reflect.New(reflect.SliceOf(rel.elem))
+	for start := 0; start < len(keys); start += maxVariables {
+		end := min(start+maxVariables, len(keys))
+		q := (&SelectQuery{}).From(rel.table).Where(In(childColumn, keys[start:end]...))
+		if _, err := query(ctx, r, q, children.Interface()); err != nil {
+			return err
+		}
+	}
+	children = children.Elem()
+
+	matches := make(map[interface{}][]reflect.Value)
+	values := make([]reflect.Value, children.Len())
+	for i := range values {
+		values[i] = children.Index(i)
+		key, ok, err := relationKey(childInfo, values[i], childColumn)
+		if err != nil {
+			return err
+		}
+		if ok {
+			matches[key] = append(matches[key], values[i])
+		}
+	}
+
+	// Load nested relations before the children are copied into the parents
+	if len(nested) > 0 {
+		if err := preloadRelations(ctx, r, rel.elem, values, nested); err != nil {
+			return err
+		}
+	}
+
+	for i, parent := range parents {
+		rel.assign(parent.Field(rel.index), matches[parentKeys[i]])
+	}
+	return nil
+}
+
+// assign sets the relation field to the matching children
+func (rel *relation) assign(field reflect.Value, children []reflect.Value) {
+	switch field.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(field.Type(), 0, len(children))
+		for _, child := range children {
+			if field.Type().Elem().Kind() == reflect.Ptr {
+				child = child.Addr()
+			}
+			s = reflect.Append(s, child)
+		}
+		field.Set(s)
+	case reflect.Ptr:
+		if len(children) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(children[0].Addr())
+		}
+	default:
+		if len(children) == 0 {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(children[0])
+		}
+	}
+}
+
+// defaultKey returns the primary key column of a struct, or id if it has none
+func defaultKey(info *structInfo) string {
+	if len(info.keys) > 0 {
+		return info.keys[0]
+	}
+	return "id"
+}
+
+// relationKey returns the value of column of the struct v normalized for use
+// as a map key, so an int field matches an int64 field and []byte matches
+// string. It reports false if the value is NULL and returns an error if the
+// value cannot be used as a map key.
+func relationKey(info *structInfo, v reflect.Value, column string) (interface{}, bool, error) {
+	f, ok := info.fieldValue(v, column)
+	if !ok {
+		return nil, false, nil
+	}
+	if valuer, ok := f.Interface().(driver.Valuer); ok {
+		value, err := valuer.Value()
+		if err != nil || value == nil {
+			return nil, false, nil
+		}
+		f = reflect.ValueOf(value)
+	}
+	for f.Kind() == reflect.Ptr || f.Kind() == reflect.Interface {
+		if f.IsNil() {
+			return nil, false, nil
+		}
+		f = f.Elem()
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f.Int(), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(f.Uint()), true, nil
+	case reflect.Slice:
+		if f.Type().Elem().Kind() == reflect.Uint8 {
+			return string(f.Bytes()), true, nil
+		}
+	}
+	if !f.Comparable() {
+		return nil, false, fmt.Errorf("qb: column %s of %s has a value of type %s that cannot be used as a relation key", column, v.Type(), f.Type())
+	}
+	return f.Interface(), true, nil
+}
@@ -8,40 +8,124 @@ import (
 
 // SelectQuery represents a SELECT sql query
 type SelectQuery struct {
+	withClause
 	whereClause
-	table      string
-	columns    []string
-	joins      []string
-	joinParams []interface{}
-	limit      string
-	offset     string
-	cte        string
-	cteParams  []interface{}
-	orderBys   []string
-	groupBys   []string
+	distinct  bool
+	table     string
+	from      Builder
+	columns   []Builder
+	exprs     []Builder
+	joins     []Builder
+	limit     string
+	offset    string
+	orderBys  []string
+	groupBys  []string
+	havings   []Builder
+	windows   []namedWindow
+	compounds []compound
+	strict    StrictMode
+	preloads  []string
 }
 
-// From is used to set the table to select from
-func (q *SelectQuery) From(table string) *SelectQuery {
-	q.table = table
+// compound is a SELECT query combined with the preceding ones using a
+// compound operator such as UNION
+type compound struct {
+	operator string
+	query    *SelectQuery
+}
+
+// From is used to set the table to select from. The table is either a table
+// name or a Builder such as another SelectQuery, with an optional alias
+func (q *SelectQuery) From(table interface{}, alias ...string) *SelectQuery {
+	if name, ok := table.(string); ok {
+		q.table = strings.Join(append([]string{name}, alias...), " ")
+		q.from = nil
+	} else {
+		q.from = tableExpr(table, alias)
+	}
+	return q
+}
+
+// Distinct makes the query behave using SELECT DISTINCT
+func (q *SelectQuery) Distinct() *SelectQuery {
+	q.distinct = true
 	return q
 }
 
-// Columns determines with columns to select
+// Columns determines with columns to select, replacing the ones set by a
+// previous call. The columns are selected in the order in which they are
+// passed, followed by the ones added using ColumnExprs.
 func (q *SelectQuery) Columns(columns ...string) *SelectQuery {
-	q.columns = columns
+	q.columns = make([]Builder, 0, len(columns))
+	for _, column := range columns {
+		q.columns = append(q.columns, Expr(column))
+	}
+	return q
+}
+
+// ColumnExprs adds columns to select given as a Builder such as
+// qb.As(subquery, "alias"), see Columns for the order they are selected in
+func (q *SelectQuery) ColumnExprs(columns ...Builder) *SelectQuery {
+	q.exprs = append(q.exprs, columns...)
 	return q
 }
 
+// selectColumns returns the columns set using Columns followed by the ones
+// added using ColumnExprs
+func (q *SelectQuery) selectColumns() []Builder {
+	if len(q.exprs) == 0 {
+		return q.columns
+	}
+	return append(append([]Builder{}, q.columns...), q.exprs...)
+}
+
 // Join adds a join to the select query
 func (q *SelectQuery) Join(join string, params ...interface{}) *SelectQuery {
-	q.joins = append(q.joins, join)
-	q.joinParams = append(q.joinParams, params...)
+	q.joins = append(q.joins, Expr(join, params...))
 	return q
 }
 
-// Where adds a where clause to the select query using *AND* strategy
-func (q *SelectQuery) Where(condition string, params ...interface{}) *SelectQuery {
+// InnerJoin adds an INNER JOIN to the select query. The table is either a
+// table name or a Builder such as qb.As(subquery, "alias"). The condition is
+// either a raw SQL string with params, a Builder such as qb.Eq or qb.Using.
+func (q *SelectQuery) InnerJoin(table interface{}, condition interface{}, params ...interface{}) *SelectQuery {
+	q.joins = append(q.joins, newJoin("INNER JOIN", table, condition, params))
+	return q
+}
+
+// LeftJoin adds a LEFT JOIN to the select query, see InnerJoin
+func (q *SelectQuery) LeftJoin(table interface{}, condition interface{}, params ...interface{}) *SelectQuery {
+	q.joins = append(q.joins, newJoin("LEFT JOIN", table, condition, params))
+	return q
+}
+
+// RightJoin adds a RIGHT JOIN to the select query, see InnerJoin
+func (q *SelectQuery) RightJoin(table interface{}, condition interface{}, params ...interface{}) *SelectQuery {
+	q.joins = append(q.joins, newJoin("RIGHT JOIN", table, condition, params))
+	return q
+}
+
+// FullJoin adds a FULL JOIN to the select query, see InnerJoin
+func (q *SelectQuery) FullJoin(table interface{}, condition interface{}, params ...interface{}) *SelectQuery {
+	q.joins = append(q.joins, newJoin("FULL JOIN", table, condition, params))
+	return q
+}
+
+// CrossJoin adds a CROSS JOIN without a condition to the select query
+func (q *SelectQuery) CrossJoin(table interface{}) *SelectQuery {
+	q.joins = append(q.joins, &joinExpr{operator: "CROSS JOIN", table: tableExpr(table, nil)})
+	return q
+}
+
+// NaturalJoin adds a NATURAL JOIN without a condition to the select query
+func (q *SelectQuery) NaturalJoin(table interface{}) *SelectQuery {
+	q.joins = append(q.joins, &joinExpr{operator: "NATURAL JOIN", table: tableExpr(table, nil)})
+	return q
+}
+
+// Where adds a where clause to the select query using *AND* strategy. The
+// condition is either a raw SQL string with params or a Builder such as qb.Or
+func (q *SelectQuery) Where(condition interface{}, params ...interface{}) *SelectQuery {
 	q.addWhere(condition, params...)
 	return q
 }
@@ -58,6 +142,31 @@ func (q *SelectQuery) GroupBy(column string) *SelectQuery {
 	return q
 }
 
+// Having adds a HAVING clause to the SELECT query using *AND* strategy. The
+// condition is either a raw SQL string with params or a Builder such as qb.Or
+func (q *SelectQuery) Having(condition interface{}, params ...interface{}) *SelectQuery {
+	q.havings = append(q.havings, toExpr(condition, params...))
+	return q
+}
+
+// Window adds a named window definition to the WINDOW clause of the SELECT
+// query, to be used by window functions as in qb.RowNumber().Over(name)
+func (q *SelectQuery) Window(name string, def *WindowDef) *SelectQuery {
+	q.windows = append(q.windows, namedWindow{name: name, def: def})
+	return q
+}
+
+// Strict sets the StrictMode used when loading the results of the SELECT
+// query into structs, in addition to the mode of the DB and the context
+func (q *SelectQuery) Strict(mode StrictMode) *SelectQuery {
+	q.strict = mode
+	return q
+}
+
+func (q *SelectQuery) strictMode() StrictMode {
+	return q.strict
+}
+
 // Limit adds a LIMIT clause to the SELECT query
 func (q *SelectQuery) Limit(limit int) *SelectQuery {
 	q.limit = fmt.Sprintf("%d", limit)
@@ -70,69 +179,198 @@ func (q *SelectQuery) Offset(offset int) *SelectQuery {
 	return q
 }
 
-// With adds a Common Table Expression to the beginning of the query
-func (q *SelectQuery) With(cte string, params ...interface{}) *SelectQuery {
-	q.cte = cte
-	q.cteParams = append(q.cteParams, params...)
+// With adds a Common Table Expression to the beginning of the query. Wrap the
+// query in qb.Materialized or qb.NotMaterialized to add a hint.
+func (q *SelectQuery) With(name string, query Builder) *SelectQuery {
+	q.addWith(name, nil, query, false)
+	return q
+}
+
+// WithRecursive adds a recursive Common Table Expression with the given
+// columns to the beginning of the query
+func (q *SelectQuery) WithRecursive(name string, columns []string, query Builder) *SelectQuery {
+	q.addWith(name, columns, query, true)
+	return q
+}
+
+// Union combines the results of this query with those of the given queries
+// using UNION. OrderBy, Limit and Offset apply to the combined results.
+func (q *SelectQuery) Union(queries ...*SelectQuery) *SelectQuery {
+	return q.compound("UNION", queries)
+}
+
+// UnionAll combines the results of this query with those of the given queries
+// using UNION ALL. OrderBy, Limit and Offset apply to the combined results.
+func (q *SelectQuery) UnionAll(queries ...*SelectQuery) *SelectQuery {
+	return q.compound("UNION ALL", queries)
+}
+
+// Intersect combines the results of this query with those of the given
+// queries using INTERSECT. OrderBy, Limit and Offset apply to the combined
+// results.
+func (q *SelectQuery) Intersect(queries ...*SelectQuery) *SelectQuery {
+	return q.compound("INTERSECT", queries)
+}
+
+// Except combines the results of this query with those of the given queries
+// using EXCEPT. OrderBy, Limit and Offset apply to the combined results.
+func (q *SelectQuery) Except(queries ...*SelectQuery) *SelectQuery {
+	return q.compound("EXCEPT", queries)
+}
+
+func (q *SelectQuery) compound(operator string, queries []*SelectQuery) *SelectQuery {
+	for _, query := range queries {
+		q.compounds = append(q.compounds, compound{operator: operator, query: query})
+	}
 	return q
 }
 
 // Params returns the parameters for this query
 func (q *SelectQuery) Params() []interface{} {
-	total := len(q.cteParams) + len(q.joinParams) + len(q.whereClause.params)
-	if total == 0 {
-		return nil
-	}
-	p := make([]interface{}, 0, total)
-	p = append(p, q.cteParams...)
-	p = append(p, q.joinParams...)
-	p = append(p, q.whereClause.params...)
+	var p []interface{}
+	p = append(p, q.withParams()...)
+	p = append(p, q.coreParams()...)
+	for _, c := range q.compounds {
+		p = append(p, c.query.Params()...)
+	}
+	return p
+}
+
+// coreParams returns the parameters of the SELECT core, excluding the CTE and
+// compound queries
+func (q *SelectQuery) coreParams() []interface{} {
+	var p []interface{}
+	p = append(p, joinedParams(q.selectColumns())...)
+	if q.from != nil {
+		p = append(p, q.from.Params()...)
+	}
+	p = append(p, joinedParams(q.joins)...)
+	p = append(p, q.whereParams()...)
+	p = append(p, joinedParams(q.havings)...)
 	return p
 }
 
 // Build renders the SELECT query as a string
 func (q *SelectQuery) Build(buf *bytes.Buffer) error {
-	if q.cte != "" {
-		buf.WriteString(q.cte)
+	return annotate(q.build(buf), "SELECT", "")
+}
+
+func (q *SelectQuery) build(buf *bytes.Buffer) error {
+	if err := q.writeWith(buf); err != nil {
+		return err
+	}
+
+	if err := q.writeCore(buf); err != nil {
+		return err
+	}
+
+	for _, c := range q.compounds {
 		buf.WriteString(" ")
+		buf.WriteString(c.operator)
+		buf.WriteString(" ")
+		if err := c.query.writeCompoundMember(buf); err != nil {
+			return err
+		}
+	}
+
+	if len(q.orderBys) != 0 {
+		buf.WriteString(" ORDER BY ")
+		buf.WriteString(strings.Join(q.orderBys, ", "))
+	}
+
+	if q.limit != "" {
+		buf.WriteString(" LIMIT ")
+		buf.WriteString(q.limit)
+	}
+
+	if q.offset != "" {
+		buf.WriteString(" OFFSET ")
+		buf.WriteString(q.offset)
+	}
+
+	return nil
+}
+
+// writeCompoundMember renders the query as part of a compound SELECT. SQLite
+// does not allow a member to have its own ORDER BY, LIMIT or WITH clause and
+// evaluates compound operators from left to right, so such members and
+// compound members are wrapped in a subquery.
+func (q *SelectQuery) writeCompoundMember(buf *bytes.Buffer) error {
+	if len(q.ctes) == 0 && len(q.compounds) == 0 && len(q.orderBys) == 0 && q.limit == "" && q.offset == "" {
+		return q.writeCore(buf)
+	}
+	buf.WriteString("SELECT * FROM ")
+	return writeSubquery(buf, q)
+}
+
+// writeCore renders the SELECT core up to and including the WINDOW clause
+func (q *SelectQuery) writeCore(buf *bytes.Buffer) error {
+	if q.table == "" && q.from == nil {
+		return &BuildError{Clause: "FROM", Reason: "no table to select from"}
 	}
 
 	buf.WriteString("SELECT ")
 
-	if len(q.columns) > 0 {
-		buf.WriteString(strings.Join(q.columns, ", "))
+	if q.distinct {
+		buf.WriteString("DISTINCT ")
+	}
+
+	if columns := q.selectColumns(); len(columns) > 0 {
+		for i, column := range columns {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if err := writeExpr(buf, column); err != nil {
+				return annotate(err, "", "columns")
+			}
+		}
 	} else {
 		buf.WriteString("*")
 	}
 
 	buf.WriteString(" FROM ")
-	buf.WriteString(q.table)
+	if q.from != nil {
+		if err := q.from.Build(buf); err != nil {
+			return annotate(err, "", "FROM")
+		}
+	} else {
+		buf.WriteString(q.table)
+	}
 
 	for _, join := range q.joins {
 		buf.WriteString(" ")
-		buf.WriteString(join)
+		if err := join.Build(buf); err != nil {
+			return err
+		}
 	}
 
-	q.writeWhere(buf)
+	if err := q.writeWhere(buf); err != nil {
+		return err
+	}
 
 	if len(q.groupBys) != 0 {
 		buf.WriteString(" GROUP BY ")
 		buf.WriteString(strings.Join(q.groupBys, ", "))
 	}
 
-	if len(q.orderBys) != 0 {
-		buf.WriteString(" ORDER BY ")
-		buf.WriteString(strings.Join(q.orderBys, ", "))
+	if len(q.havings) != 0 {
+		buf.WriteString(" HAVING ")
+		if err := writeJoined(buf, q.havings, " AND "); err != nil {
+			return annotate(err, "", "HAVING")
+		}
 	}
 
-	if q.limit != "" {
-		buf.WriteString(" LIMIT ")
-		buf.WriteString(q.limit)
-	}
-
-	if q.offset != "" {
-		buf.WriteString(" OFFSET ")
-		buf.WriteString(q.offset)
+	for i, window := range q.windows {
+		if i == 0 {
+			buf.WriteString(" WINDOW ")
+		} else {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(window.name)
+		buf.WriteString(" AS ")
+		if err := writeSubquery(buf, window.def); err != nil {
+			return annotate(err, "", "WINDOW")
+		}
 	}
 
 	return nil
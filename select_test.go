@@ -2,6 +2,7 @@ package qb
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -24,6 +25,38 @@ func TestSelectQuery(t *testing.T) {
 			},
 			result: "SELECT * FROM fuu",
 		},
+		{
+			name: "select without table",
+			query: func() *SelectQuery {
+				query := &SelectQuery{}
+				query.Columns("bar")
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "select with raw or conditions",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Where("column1 = ? OR column2 = ?", 1, 2)
+				query.Where("column3 = ?", 3)
+				query.GroupBy("column1")
+				query.Having("COUNT(*) > ? OR MAX(column3) IS NULL", 1)
+				query.Having("MIN(column3) > ?", 0)
+				return query
+			},
+			result: "SELECT * FROM fuu WHERE (column1 = ? OR column2 = ?) AND column3 = ? GROUP BY column1 HAVING (COUNT(*) > ? OR MAX(column3) IS NULL) AND MIN(column3) > ?",
+			values: []interface{}{1, 2, 3, 1, 0},
+		},
+		{
+			name: "select with missing where param",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Where("bar = ? AND baz = ?", 1)
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
 		{
 			name: "select specific columns",
 			query: func() *SelectQuery {
@@ -63,6 +96,80 @@ func TestSelectQuery(t *testing.T) {
 			result: "SELECT * FROM fuu WHERE column1 = ? AND column2 IS NULL",
 			values: []interface{}{"fuu"},
 		},
+		{
+			name: "select where with predicate expression",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Where("column1 = ?", "fuu")
+				query.Where(Or(Eq("column2", 1), And(Gt("column3", 2), IsNull("column4"))))
+				return query
+			},
+			result: "SELECT * FROM fuu WHERE column1 = ? AND (column2 = ? OR (column3 > ? AND column4 IS NULL))",
+			values: []interface{}{"fuu", 1, 2},
+		},
+		{
+			name: "select where and join with slices",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Join("JOIN bar ON bar.fuu_id = fuu.id AND bar.type IN (?)", []string{"a", "b"})
+				query.Where("fuu.id IN (?)", []int64{1, 2, 3})
+				return query
+			},
+			result: "SELECT * FROM fuu JOIN bar ON bar.fuu_id = fuu.id AND bar.type IN (?, ?) WHERE fuu.id IN (?, ?, ?)",
+			values: []interface{}{"a", "b", int64(1), int64(2), int64(3)},
+		},
+		{
+			name: "select from subquery",
+			query: func() *SelectQuery {
+				sub := &SelectQuery{table: "bar"}
+				sub.Where("type = ?", "a")
+				query := &SelectQuery{}
+				query.From(sub, "b")
+				query.Where("b.id > ?", 10)
+				return query
+			},
+			result: "SELECT * FROM (SELECT * FROM bar WHERE type = ?) AS b WHERE b.id > ?",
+			values: []interface{}{"a", 10},
+		},
+		{
+			name: "select from table with alias",
+			query: func() *SelectQuery {
+				query := &SelectQuery{}
+				query.From("fuu", "f")
+				return query
+			},
+			result: "SELECT * FROM fuu f",
+		},
+		{
+			name: "select with subqueries in columns and where",
+			query: func() *SelectQuery {
+				count := &SelectQuery{table: "bar"}
+				count.Columns("COUNT(*)")
+				count.Where("bar.fuu_id = fuu.id AND bar.type = ?", "a")
+				ids := &SelectQuery{table: "baz"}
+				ids.Columns("fuu_id")
+				ids.Where("baz.year = ?", 2020)
+				query := &SelectQuery{table: "fuu"}
+				query.Columns("id").ColumnExprs(As(count, "cnt"))
+				query.Where("id IN ?", ids)
+				query.Where(Exists(Expr("SELECT 1 FROM qux WHERE qux.fuu_id = fuu.id AND qux.flag = ?", true)))
+				return query
+			},
+			result: "SELECT id, (SELECT COUNT(*) FROM bar WHERE bar.fuu_id = fuu.id AND bar.type = ?) AS cnt FROM fuu WHERE id IN (SELECT fuu_id FROM baz WHERE baz.year = ?) AND EXISTS (SELECT 1 FROM qux WHERE qux.fuu_id = fuu.id AND qux.flag = ?)",
+			values: []interface{}{"a", 2020, true},
+		},
+		{
+			name: "select column exprs before columns",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.ColumnExprs(Expr("? AS flag", true))
+				query.Columns("id")
+				query.Columns("id", "name")
+				return query
+			},
+			result: "SELECT id, name, ? AS flag FROM fuu",
+			values: []interface{}{true},
+		},
 		{
 			name: "select group by",
 			query: func() *SelectQuery {
@@ -120,6 +227,147 @@ func TestSelectQuery(t *testing.T) {
 			},
 			result: "SELECT * FROM fuu LIMIT 10 OFFSET 10",
 		},
+		{
+			name: "select union with trailing order by and limit",
+			query: func() *SelectQuery {
+				bar := &SelectQuery{table: "bar"}
+				bar.Columns("id", "name")
+				bar.Where("type = ?", "b")
+				query := &SelectQuery{table: "fuu"}
+				query.Columns("id", "name")
+				query.Where("type = ?", "a")
+				query.Union(bar)
+				query.OrderBy("name", "ASC")
+				query.Limit(10)
+				return query
+			},
+			result: "SELECT id, name FROM fuu WHERE type = ? UNION SELECT id, name FROM bar WHERE type = ? ORDER BY name ASC LIMIT 10",
+			values: []interface{}{"a", "b"},
+		},
+		{
+			name: "select chained compound operators",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Columns("id")
+				query.UnionAll((&SelectQuery{table: "bar"}).Columns("id"))
+				query.Intersect((&SelectQuery{table: "baz"}).Columns("id").Where("year = ?", 2020))
+				query.Except((&SelectQuery{table: "qux"}).Columns("id"))
+				return query
+			},
+			result: "SELECT id FROM fuu UNION ALL SELECT id FROM bar INTERSECT SELECT id FROM baz WHERE year = ? EXCEPT SELECT id FROM qux",
+			values: []interface{}{2020},
+		},
+		{
+			name: "select union with limited and compound members",
+			query: func() *SelectQuery {
+				latest := &SelectQuery{table: "bar"}
+				latest.Columns("id")
+				latest.OrderBy("created", "DESC")
+				latest.Limit(5)
+				nested := &SelectQuery{table: "baz"}
+				nested.Columns("id")
+				nested.Except((&SelectQuery{table: "qux"}).Columns("id").Where("flag = ?", true))
+				query := &SelectQuery{table: "fuu"}
+				query.Columns("id")
+				query.Where("id > ?", 1)
+				query.Union(latest, nested)
+				return query
+			},
+			result: "SELECT id FROM fuu WHERE id > ? UNION SELECT * FROM (SELECT id FROM bar ORDER BY created DESC LIMIT 5) UNION SELECT * FROM (SELECT id FROM baz EXCEPT SELECT id FROM qux WHERE flag = ?)",
+			values: []interface{}{1, true},
+		},
+		{
+			name: "select with multiple ctes",
+			query: func() *SelectQuery {
+				active := &SelectQuery{table: "users"}
+				active.Where("active = ?", true)
+				totals := &SelectQuery{table: "orders"}
+				totals.Columns("user_id", "SUM(amount) AS total")
+				totals.Where("year = ?", 2020)
+				totals.GroupBy("user_id")
+				query := &SelectQuery{table: "a"}
+				query.With("a", active)
+				query.With("t", Materialized(totals))
+				query.Join("JOIN t ON t.user_id = a.id")
+				query.Where("t.total > ?", 100)
+				return query
+			},
+			result: "WITH a AS (SELECT * FROM users WHERE active = ?), t AS MATERIALIZED (SELECT user_id, SUM(amount) AS total FROM orders WHERE year = ? GROUP BY user_id) SELECT * FROM a JOIN t ON t.user_id = a.id WHERE t.total > ?",
+			values: []interface{}{true, 2020, 100},
+		},
+		{
+			name: "select with recursive cte",
+			query: func() *SelectQuery {
+				root := &SelectQuery{table: "categories"}
+				root.Columns("id", "parent_id", "0")
+				root.Where("id = ?", 1)
+				children := &SelectQuery{table: "categories c"}
+				children.Columns("c.id", "c.parent_id", "tree.depth + 1")
+				children.Join("JOIN tree ON c.parent_id = tree.id")
+				query := &SelectQuery{table: "tree"}
+				query.With("roots", NotMaterialized(Expr("SELECT ? AS id", 1)))
+				query.WithRecursive("tree", []string{"id", "parent_id", "depth"}, root.UnionAll(children))
+				query.OrderBy("depth", "ASC")
+				return query
+			},
+			result: "WITH RECURSIVE roots AS NOT MATERIALIZED (SELECT ? AS id), tree(id, parent_id, depth) AS (SELECT id, parent_id, 0 FROM categories WHERE id = ? UNION ALL SELECT c.id, c.parent_id, tree.depth + 1 FROM categories c JOIN tree ON c.parent_id = tree.id) SELECT * FROM tree ORDER BY depth ASC",
+			values: []interface{}{1, 1},
+		},
+		{
+			name: "select distinct with having",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "fuu"}
+				query.Distinct()
+				query.Columns("type", "COUNT(*) AS cnt")
+				query.Where("year = ?", 2020)
+				query.GroupBy("type")
+				query.Having("COUNT(*) > ?", 5)
+				query.Having(Or(Expr("MAX(amount) > ?", 100), Expr("MIN(amount) < ?", 0)))
+				query.OrderBy("cnt", "DESC")
+				return query
+			},
+			result: "SELECT DISTINCT type, COUNT(*) AS cnt FROM fuu WHERE year = ? GROUP BY type HAVING COUNT(*) > ? AND (MAX(amount) > ? OR MIN(amount) < ?) ORDER BY cnt DESC",
+			values: []interface{}{2020, 5, 100, 0},
+		},
+		{
+			name: "select with window functions",
+			query: func() *SelectQuery {
+				query := &SelectQuery{table: "sales"}
+				query.Columns("region")
+				query.ColumnExprs(
+					As(RowNumber().Over("w"), "rank"),
+					As(Lag("amount", 1).Over("w"), "previous"),
+					As(WindowFn("SUM(amount)").Over(Window().PartitionBy("region").OrderBy("day", "ASC").Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW")), "running_total"),
+				)
+				query.Where("year = ?", 2020)
+				query.Window("w", Window().PartitionBy("region").OrderBy("amount", "DESC"))
+				query.Limit(10)
+				return query
+			},
+			result: "SELECT region, ROW_NUMBER() OVER w AS rank, LAG(amount, 1) OVER w AS previous, SUM(amount) OVER (PARTITION BY region ORDER BY day ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS running_total FROM sales WHERE year = ? WINDOW w AS (PARTITION BY region ORDER BY amount DESC) LIMIT 10",
+			values: []interface{}{2020},
+		},
+		{
+			name: "select with typed joins",
+			query: func() *SelectQuery {
+				counts := &SelectQuery{table: "plays"}
+				counts.Columns("track_id", "COUNT(*) AS plays")
+				counts.Where("year = ?", 2020)
+				counts.GroupBy("track_id")
+				query := &SelectQuery{table: "track t"}
+				query.InnerJoin(As("artist", "a"), Eq("a.id", Expr("t.artist")))
+				query.LeftJoin(As(counts, "c"), And(Eq("c.track_id", Expr("t.id")), Gt("c.plays", 10)))
+				query.LeftJoin("album", Using("album_id"))
+				query.CrossJoin("settings")
+				query.NaturalJoin("track_meta")
+				query.RightJoin("genre g", "g.id = t.genre AND g.visible = ?", true)
+				query.FullJoin("label l", "l.id = a.label")
+				query.Where("t.name LIKE ?", "a%")
+				return query
+			},
+			result: "SELECT * FROM track t INNER JOIN artist AS a ON a.id = t.artist LEFT JOIN (SELECT track_id, COUNT(*) AS plays FROM plays WHERE year = ? GROUP BY track_id) AS c ON (c.track_id = t.id AND c.plays > ?) LEFT JOIN album USING (album_id) CROSS JOIN settings NATURAL JOIN track_meta RIGHT JOIN genre g ON g.id = t.genre AND g.visible = ? FULL JOIN label l ON l.id = a.label WHERE t.name LIKE ?",
+			values: []interface{}{2020, 10, true, "a%"},
+		},
 		{
 			name: "select with many things combined",
 			query: func() *SelectQuery {
@@ -143,8 +391,10 @@ func TestSelectQuery(t *testing.T) {
 			query := tst.query()
 			buf := bytes.Buffer{}
 
-			if err := query.Build(&buf); err != tst.err {
-				t.Fatal(err)
+			if err := query.Build(&buf); !errors.Is(err, tst.err) {
+				t.Fatalf("got: %v -- expected: %v", err, tst.err)
+			} else if tst.err != nil {
+				return
 			} else if buf.String() != tst.result {
 				t.Fatalf("got: %s -- expected: %s", buf.String(), tst.result)
 			} else if !reflect.DeepEqual(query.Params(), tst.values) {
@@ -153,3 +403,12 @@ func TestSelectQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectQueryJoinWithoutCondition(t *testing.T) {
+	query := &SelectQuery{table: "fuu"}
+	query.LeftJoin("bar", nil)
+
+	if err := query.Build(&bytes.Buffer{}); err == nil {
+		t.Fatal("Expected an error for a LEFT JOIN without condition")
+	}
+}
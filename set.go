@@ -0,0 +1,63 @@
+package qb
+
+import (
+	"bytes"
+)
+
+// setClause is a single column = value assignment of a SET clause
+type setClause struct {
+	column string
+	value  Builder
+}
+
+func (s setClause) build(buf *bytes.Buffer) error {
+	if s.column != "" {
+		buf.WriteString(s.column)
+		buf.WriteString(" = ")
+	}
+	return s.value.Build(buf)
+}
+
+// setValue creates an assignment of a value. A Builder value is rendered as
+// an expression, any other value is bound as a single parameter, even if it is
+// a slice.
+func setValue(column string, value interface{}) setClause {
+	if b, ok := value.(Builder); ok {
+		return setClause{column: column, value: &subqueryExpr{b}}
+	}
+	return setClause{column: column, value: paramExpr{value}}
+}
+
+// paramExpr is a single ? placeholder bound to a value as is
+type paramExpr struct {
+	value interface{}
+}
+
+func (e paramExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString("?")
+	return nil
+}
+
+func (e paramExpr) Params() []interface{} {
+	return []interface{}{e.value}
+}
+
+func writeSets(buf *bytes.Buffer, sets []setClause) error {
+	for i, set := range sets {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if err := set.build(buf); err != nil {
+			return annotate(err, "", "SET")
+		}
+	}
+	return nil
+}
+
+func setParams(sets []setClause) []interface{} {
+	var p []interface{}
+	for _, set := range sets {
+		p = append(p, set.value.Params()...)
+	}
+	return p
+}
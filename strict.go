@@ -0,0 +1,107 @@
+package qb
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+var (
+	strictContextKey = contextKey("strict")
+)
+
+// StrictMode determines which mismatches between result columns and struct
+// fields are reported as a *MappingError when loading rows into structs
+type StrictMode int
+
+const (
+	// StrictColumns reports result columns without a matching struct field
+	StrictColumns StrictMode = 1 << iota
+
+	// StrictFields reports struct fields tagged as required, as in
+	// `db:"name,required"`, that have no matching result column
+	StrictFields
+)
+
+// GetStrictCtx extracts the qb.StrictMode from the context, or 0 if none is set.
+func GetStrictCtx(ctx context.Context) StrictMode {
+	mode, _ := ctx.Value(strictContextKey).(StrictMode)
+	return mode
+}
+
+// WithStrict adds a qb.StrictMode to the context
+func WithStrict(ctx context.Context, mode StrictMode) context.Context {
+	return context.WithValue(ctx, strictContextKey, mode)
+}
+
+// strictDefaults holds the default StrictMode of every DB and Tx set using
+// SetStrict. It is kept outside of their structs so they can still be created
+// using a positional literal such as &qb.DB{db}.
+var strictDefaults sync.Map // map[interface{}]StrictMode
+
+func strictDefault(owner interface{}) StrictMode {
+	mode, _ := strictDefaults.Load(owner)
+	m, _ := mode.(StrictMode)
+	return m
+}
+
+func setStrictDefault(owner interface{}, mode StrictMode) {
+	if mode == 0 {
+		strictDefaults.Delete(owner)
+	} else {
+		strictDefaults.Store(owner, mode)
+	}
+}
+
+// withStrictDefault adds the default mode of a DB or Tx to the mode in ctx
+func withStrictDefault(ctx context.Context, mode StrictMode) context.Context {
+	current := GetStrictCtx(ctx)
+	if current&mode == mode {
+		return ctx
+	}
+	return WithStrict(ctx, current|mode)
+}
+
+// strictModer is implemented by a DB, Tx or query with its own strict mode
+type strictModer interface {
+	strictMode() StrictMode
+}
+
+// strictModeOf combines the mode in ctx with the modes of the given values
+func strictModeOf(ctx context.Context, values ...interface{}) StrictMode {
+	mode := GetStrictCtx(ctx)
+	for _, v := range values {
+		if s, ok := v.(strictModer); ok {
+			mode |= s.strictMode()
+		}
+	}
+	return mode
+}
+
+// checkMapping returns a *MappingError if columns and the fields of the
+// struct type t do not match according to mode
+func checkMapping(mode StrictMode, t reflect.Type, columns []string, plan *scanPlan) error {
+	err := &MappingError{Type: t}
+	if mode&StrictColumns != 0 {
+		for i, path := range plan.paths {
+			if path == nil {
+				err.Columns = append(err.Columns, columns[i])
+			}
+		}
+	}
+	if mode&StrictFields != 0 {
+		scanned := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			scanned[column] = true
+		}
+		for _, field := range getStructInfo(t).fields {
+			if field.options.Contains("required") && !scanned[field.name] {
+				err.Fields = append(err.Fields, field.name)
+			}
+		}
+	}
+	if len(err.Columns) == 0 && len(err.Fields) == 0 {
+		return nil
+	}
+	return err
+}
@@ -3,7 +3,6 @@ package qb
 import (
 	"context"
 	"database/sql"
-	"errors"
 )
 
 var (
@@ -26,6 +25,28 @@ type Tx struct {
 	*sql.Tx
 }
 
+// SetStrict sets the default StrictMode for all queries loaded through the Tx,
+// which is inherited from the DB that started it
+func (tx *Tx) SetStrict(mode StrictMode) {
+	setStrictDefault(tx, mode)
+}
+
+func (tx *Tx) strictMode() StrictMode {
+	return strictDefault(tx)
+}
+
+// Commit commits the transaction
+func (tx *Tx) Commit() error {
+	setStrictDefault(tx, 0)
+	return mapError(tx.Tx.Commit())
+}
+
+// Rollback aborts the transaction
+func (tx *Tx) Rollback() error {
+	setStrictDefault(tx, 0)
+	return tx.Tx.Rollback()
+}
+
 // Select creates and returns a new SelectQuery
 func (tx *Tx) Select() *SelectQuery { return &SelectQuery{} }
 
@@ -43,9 +64,22 @@ func (tx *Tx) Exec(ctx context.Context, b Builder) (sql.Result, error) {
 	return exec(ctx, tx.Tx, b)
 }
 
-// Load executes a read query within the transaction and scans the results into dest
+// ExecReturning executes a write query with a RETURNING clause within the
+// transaction and scans the returned rows into dest
+func (tx *Tx) ExecReturning(ctx context.Context, b Builder, dest interface{}) (int, error) {
+	return execReturning(withStrictDefault(ctx, tx.strictMode()), tx.Tx, b, dest)
+}
+
+// Load executes a read query within the transaction and scans the results into
+// dest, see DB.Load
 func (tx *Tx) Load(ctx context.Context, b Builder, dest interface{}) (int, error) {
-	return query(ctx, tx.Tx, b, dest)
+	return query(withStrictDefault(ctx, tx.strictMode()), tx.Tx, b, dest)
+}
+
+// Rows executes a read query within the transaction and returns the raw
+// rows, which must be closed
+func (tx *Tx) Rows(ctx context.Context, b Builder) (*sql.Rows, error) {
+	return queryRows(ctx, tx.Tx, b)
 }
 
 // LoadValue executes a read query within the transaction and scans the scalar result into dest
@@ -55,7 +89,7 @@ func (tx *Tx) LoadValue(ctx context.Context, b Builder, dest interface{}) error
 		return err
 	}
 	if rows == 0 {
-		return errors.New("no records returned")
+		return ErrNoRows
 	}
 	return nil
 }
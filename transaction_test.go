@@ -2,6 +2,8 @@ package qb
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
 )
 
@@ -60,8 +62,10 @@ func TestTransactionsRollback(t *testing.T) {
 	// Generate a conflict
 	if _, err := tx.Exec(ctx, tx.Update().Table("animals").Set("name", "fuu").Where("name = ?", "bar")); err == nil {
 		t.Fatalf("Expected unique constraint to kick in when UPDATE but it did not")
-	} else if err.Error() != "constraint failed: UNIQUE constraint failed: animals.name (2067)" {
-		t.Fatalf("got: %s -- expected: UNIQUE constraint failed: animals.name", err.Error())
+	} else if !errors.Is(err, ErrUniqueViolation) {
+		t.Fatalf("got: %s -- expected: %s", err, ErrUniqueViolation)
+	} else if e := (*Error)(nil); !errors.As(err, &e) || e.Table != "animals" || !reflect.DeepEqual(e.Columns, []string{"name"}) {
+		t.Fatalf("Expected the violation of animals.name but got %v", e)
 	}
 
 	// Rollback the transaction
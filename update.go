@@ -3,16 +3,39 @@ package qb
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"strings"
 )
 
 // UpdateQuery represents a UPDATE sql query
 type UpdateQuery struct {
+	withClause
 	whereClause
+	or        ConflictResolution
 	table     string
-	columns   []string
-	values    []interface{}
+	sets      []setClause
+	from      Builder
+	orderBys  []string
+	limit     string
 	returning []string
+	all       bool
+	maxRows   int
+}
+
+// Or sets the conflict resolution algorithm, as in UPDATE OR REPLACE
+func (q *UpdateQuery) Or(resolution ConflictResolution) *UpdateQuery {
+	q.or = resolution
+	return q
+}
+
+// OrIgnore make the query behave using UPDATE OR IGNORE
+func (q *UpdateQuery) OrIgnore() *UpdateQuery {
+	return q.Or(ConflictIgnore)
+}
+
+// OrReplace make the query behave using UPDATE OR REPLACE
+func (q *UpdateQuery) OrReplace() *UpdateQuery {
+	return q.Or(ConflictReplace)
 }
 
 // Table is used to set the table to update
@@ -21,50 +44,172 @@ func (q *UpdateQuery) Table(table string) *UpdateQuery {
 	return q
 }
 
-// Set adds a column = value statement to the UPDATE query's SET clause
+// Set adds a column = value statement to the UPDATE query's SET clause. A
+// Builder value such as qb.Expr or a scalar SelectQuery is rendered as an
+// expression instead of being bound as a parameter.
 func (q *UpdateQuery) Set(column string, values ...interface{}) *UpdateQuery {
-	q.columns = append(q.columns, column)
-	q.values = append(q.values, values...)
+	if len(values) == 1 {
+		q.sets = append(q.sets, setValue(column, values[0]))
+	} else {
+		q.sets = append(q.sets, setClause{column: column, value: Expr("?", values...)})
+	}
+	return q
+}
+
+// SetExpr adds a column = expression statement to the UPDATE query's SET
+// clause, e.g. SetExpr("hits", "hits + ?", 1)
+func (q *UpdateQuery) SetExpr(column string, expr string, params ...interface{}) *UpdateQuery {
+	q.sets = append(q.sets, setClause{column: column, value: Expr(expr, params...)})
+	return q
+}
+
+// SetRecord adds a column = value statement for every field of the struct
+// derived from its db tags. The following tag options are supported:
+//
+//	db:"id,pk"            primary key, never SET by an UPDATE
+//	db:"id,autoincrement" like pk, and left out of an INSERT when zero
+//	db:"created,readonly" never written by an INSERT or UPDATE
+//	db:"name,omitempty"   left out of an INSERT or UPDATE when zero
+func (q *UpdateQuery) SetRecord(structValue interface{}) *UpdateQuery {
+	value := reflect.Indirect(reflect.ValueOf(structValue))
+
+	if value.Kind() == reflect.Struct {
+		columns, values := recordValues(value, true)
+		for i, column := range columns {
+			q.Set(column, values[i])
+		}
+	}
+
 	return q
 }
 
-// Where adds a where clause to the update query using *AND* strategy
-func (q *UpdateQuery) Where(condition string, params ...interface{}) *UpdateQuery {
+// Where adds a where clause to the update query using *AND* strategy. The
+// condition is either a raw SQL string with params or a Builder such as qb.Or
+func (q *UpdateQuery) Where(condition interface{}, params ...interface{}) *UpdateQuery {
 	q.addWhere(condition, params...)
 	return q
 }
 
+// From adds a FROM clause to join the table being updated with another table
+// or a Builder such as a SelectQuery, with an optional alias
+func (q *UpdateQuery) From(table interface{}, alias ...string) *UpdateQuery {
+	q.from = tableExpr(table, alias)
+	return q
+}
+
+// OrderBy adds an ORDER BY clause to the UPDATE query, which determines the
+// rows updated when combined with Limit
+func (q *UpdateQuery) OrderBy(column string, direction string) *UpdateQuery {
+	q.orderBys = append(q.orderBys, column+" "+direction)
+	return q
+}
+
+// Limit adds a LIMIT clause to the UPDATE query. The table must have a rowid.
+func (q *UpdateQuery) Limit(limit int) *UpdateQuery {
+	q.limit = fmt.Sprintf("%d", limit)
+	return q
+}
+
 // Returning specifies which columns to return after the UPDATE is successful
 func (q *UpdateQuery) Returning(returning ...string) *UpdateQuery {
 	q.returning = returning
 	return q
 }
 
+// All allows the UPDATE query to be executed without a WHERE clause or LIMIT,
+// updating all rows of the table
+func (q *UpdateQuery) All() *UpdateQuery {
+	q.all = true
+	return q
+}
+
+// MaxRows rolls back the surrounding transaction and returns ErrTooManyRows
+// if the UPDATE query updates more than max rows when executed. A max of 0
+// disables the guard, a negative max is rejected when the query is built.
+// Rows and Iterate return ErrInvalidQuery for a query with MaxRows.
+func (q *UpdateQuery) MaxRows(max int) *UpdateQuery {
+	q.maxRows = max
+	return q
+}
+
+func (q *UpdateQuery) unbounded() bool {
+	return !q.all && len(q.wheres) == 0 && q.limit == ""
+}
+
+func (q *UpdateQuery) maxRowsAffected() int {
+	return q.maxRows
+}
+
+func (q *UpdateQuery) hasReturning() bool {
+	return len(q.returning) > 0
+}
+
+// With adds a Common Table Expression to the beginning of the query. Wrap the
+// query in qb.Materialized or qb.NotMaterialized to add a hint.
+func (q *UpdateQuery) With(name string, query Builder) *UpdateQuery {
+	q.addWith(name, nil, query, false)
+	return q
+}
+
+// WithRecursive adds a recursive Common Table Expression with the given
+// columns to the beginning of the query
+func (q *UpdateQuery) WithRecursive(name string, columns []string, query Builder) *UpdateQuery {
+	q.addWith(name, columns, query, true)
+	return q
+}
+
 // Params returns all parameters for the query
 func (q *UpdateQuery) Params() []interface{} {
-	total := len(q.values) + len(q.whereClause.params)
-	if total == 0 {
-		return nil
+	p := q.withParams()
+	p = append(p, setParams(q.sets)...)
+	if q.from != nil {
+		p = append(p, q.from.Params()...)
 	}
-	p := make([]interface{}, 0, total)
-	p = append(p, q.values...)
-	p = append(p, q.whereClause.params...)
-	return p
+	return append(p, q.whereParams()...)
 }
 
 // Build renders the UPDATE query as a string
 func (q *UpdateQuery) Build(buf *bytes.Buffer) error {
-	buf.WriteString("UPDATE ")
+	return annotate(q.build(buf), "UPDATE", "")
+}
+
+func (q *UpdateQuery) build(buf *bytes.Buffer) error {
+	if q.table == "" {
+		return &BuildError{Reason: "no table to update"}
+	}
+	if len(q.sets) == 0 {
+		return &BuildError{Clause: "SET", Reason: "no columns to update"}
+	}
+	if q.from != nil && (len(q.orderBys) != 0 || q.limit != "") {
+		return &BuildError{Clause: "FROM", Reason: "does not support ORDER BY or LIMIT"}
+	}
+	if q.maxRows < 0 {
+		return &BuildError{Reason: fmt.Sprintf("MaxRows must not be negative but got %d", q.maxRows)}
+	}
+
+	if err := q.writeWith(buf); err != nil {
+		return err
+	}
+
+	q.or.write(buf, "UPDATE")
+	buf.WriteString(" ")
 	buf.WriteString(q.table)
 
 	buf.WriteString(" SET ")
-	sets := []string{}
-	for _, column := range q.columns {
-		sets = append(sets, fmt.Sprintf("%s = ?", column))
+	if err := writeSets(buf, q.sets); err != nil {
+		return err
 	}
-	buf.WriteString(strings.Join(sets, ", "))
 
-	q.writeWhere(buf)
+	if q.from != nil {
+		buf.WriteString(" FROM ")
+		if err := q.from.Build(buf); err != nil {
+			return annotate(err, "", "FROM")
+		}
+	}
+
+	if err := q.writeLimitedWhere(buf, q.table, q.orderBys, q.limit); err != nil {
+		return err
+	}
 
 	if len(q.returning) > 0 {
 		buf.WriteString(" RETURNING ")
@@ -2,8 +2,10 @@ package qb
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestUpdateQuery(t *testing.T) {
@@ -17,12 +19,30 @@ func TestUpdateQuery(t *testing.T) {
 
 	var testResults = []test{
 		{
-			name: "update nothing", // TODO consider throwing an error here
+			name: "update nothing",
 			query: func() *UpdateQuery {
 				query := &UpdateQuery{table: "fuu"}
 				return query
 			},
-			result: "UPDATE fuu SET ",
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "update without table",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{}
+				query.Set("closed", true)
+				return query
+			},
+			err: ErrInvalidQuery,
+		},
+		{
+			name: "update with negative max rows",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.Set("closed", true).Where("id = ?", 1).MaxRows(-1)
+				return query
+			},
+			err: ErrInvalidQuery,
 		},
 		{
 			name: "update and returning",
@@ -60,6 +80,147 @@ func TestUpdateQuery(t *testing.T) {
 			result: "UPDATE fuu SET closed = ?, year = ? WHERE id = ? AND name = ?",
 			values: []interface{}{true, 2020, 123, "test"},
 		},
+		{
+			name: "update with predicate expression",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.Set("closed", true)
+				query.Where(Or(Eq("id", 123), Like("name", "test%")))
+				return query
+			},
+			result: "UPDATE fuu SET closed = ? WHERE (id = ? OR name LIKE ?)",
+			values: []interface{}{true, 123, "test%"},
+		},
+		{
+			name: "update record",
+			query: func() *UpdateQuery {
+				record := struct {
+					ID        int64     `db:"id,pk,autoincrement"`
+					Name      string    `db:"name"`
+					Content   string    `db:"content,omitempty"`
+					CreatedAt time.Time `db:"created_at,readonly"`
+					Year      int
+				}{
+					ID:   123,
+					Name: "fuubar",
+				}
+				query := &UpdateQuery{table: "fuu"}
+				query.SetRecord(&record)
+				query.Where("id = ?", record.ID)
+				return query
+			},
+			result: "UPDATE fuu SET name = ?, year = ? WHERE id = ?",
+			values: []interface{}{"fuubar", 0, int64(123)},
+		},
+		{
+			name: "update or ignore",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.OrIgnore()
+				query.Set("name", "fuu")
+				query.Where("id = ?", 123)
+				return query
+			},
+			result: "UPDATE OR IGNORE fuu SET name = ? WHERE id = ?",
+			values: []interface{}{"fuu", 123},
+		},
+		{
+			name: "update or replace",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.OrReplace()
+				query.Set("name", "fuu")
+				query.Where("name = ?", "bar")
+				return query
+			},
+			result: "UPDATE OR REPLACE fuu SET name = ? WHERE name = ?",
+			values: []interface{}{"fuu", "bar"},
+		},
+		{
+			name: "update or fail",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.Or(ConflictFail)
+				query.Set("name", "fuu")
+				return query
+			},
+			result: "UPDATE OR FAIL fuu SET name = ?",
+			values: []interface{}{"fuu"},
+		},
+		{
+			name: "update with expressions",
+			query: func() *UpdateQuery {
+				latest := &SelectQuery{table: "bar"}
+				latest.Columns("MAX(created)")
+				latest.Where("bar.fuu_id = fuu.id AND bar.type = ?", "a")
+				query := &UpdateQuery{table: "fuu"}
+				query.SetExpr("hits", "hits + ?", 1)
+				query.SetExpr("updated", "CURRENT_TIMESTAMP")
+				query.Set("title", Expr("name"))
+				query.Set("deleted", nil)
+				query.Set("latest", latest)
+				query.Where("id = ?", 123)
+				return query
+			},
+			result: "UPDATE fuu SET hits = hits + ?, updated = CURRENT_TIMESTAMP, title = name, deleted = ?, latest = (SELECT MAX(created) FROM bar WHERE bar.fuu_id = fuu.id AND bar.type = ?) WHERE id = ?",
+			values: []interface{}{1, nil, "a", 123},
+		},
+		{
+			name: "update with slice value",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.Set("data", []byte("abc"))
+				query.Set("tags", []string{"a", "b"})
+				query.Where("id IN (?)", []int{1, 2})
+				return query
+			},
+			result: "UPDATE fuu SET data = ?, tags = ? WHERE id IN (?, ?)",
+			values: []interface{}{[]byte("abc"), []string{"a", "b"}, 1, 2},
+		},
+		{
+			name: "update from",
+			query: func() *UpdateQuery {
+				totals := &SelectQuery{table: "bar"}
+				totals.Columns("fuu_id", "SUM(amount) AS total")
+				totals.Where("year = ?", 2020)
+				totals.GroupBy("fuu_id")
+				query := &UpdateQuery{table: "fuu"}
+				query.Set("active", true)
+				query.From(totals, "t")
+				query.Where("t.fuu_id = fuu.id")
+				return query
+			},
+			result: "UPDATE fuu SET active = ? FROM (SELECT fuu_id, SUM(amount) AS total FROM bar WHERE year = ? GROUP BY fuu_id) AS t WHERE t.fuu_id = fuu.id",
+			values: []interface{}{true, 2020},
+		},
+		{
+			name: "update with order by and limit",
+			query: func() *UpdateQuery {
+				query := &UpdateQuery{table: "fuu"}
+				query.Set("processed", true)
+				query.Where("processed = ?", false)
+				query.OrderBy("created", "ASC")
+				query.Limit(100)
+				return query
+			},
+			result: "UPDATE fuu SET processed = ? WHERE rowid IN (SELECT rowid FROM fuu WHERE processed = ? ORDER BY created ASC LIMIT 100)",
+			values: []interface{}{true, false},
+		},
+		{
+			name: "update with cte",
+			query: func() *UpdateQuery {
+				stale := &SelectQuery{table: "fuu"}
+				stale.Columns("id")
+				stale.Where("updated < ?", "2020-01-01")
+				query := &UpdateQuery{table: "fuu"}
+				query.With("stale", stale)
+				query.Set("archived", true)
+				query.Where("id IN (SELECT id FROM stale)")
+				return query
+			},
+			result: "WITH stale AS (SELECT id FROM fuu WHERE updated < ?) UPDATE fuu SET archived = ? WHERE id IN (SELECT id FROM stale)",
+			values: []interface{}{"2020-01-01", true},
+		},
 		{
 			name: "update without where clause",
 			query: func() *UpdateQuery {
@@ -77,8 +238,10 @@ func TestUpdateQuery(t *testing.T) {
 			query := tst.query()
 			buf := bytes.Buffer{}
 
-			if err := query.Build(&buf); err != tst.err {
-				t.Fatal(err)
+			if err := query.Build(&buf); !errors.Is(err, tst.err) {
+				t.Fatalf("got: %v -- expected: %v", err, tst.err)
+			} else if tst.err != nil {
+				return
 			} else if buf.String() != tst.result {
 				t.Fatalf("got: %s -- expected: %s", buf.String(), tst.result)
 			} else if !reflect.DeepEqual(query.Params(), tst.values) {
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"database/sql/driver"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
 	"unicode"
 )
 
@@ -24,48 +27,222 @@ func camelCaseToSnakeCase(name string) string {
 	return buf.String()
 }
 
-func structMap(value reflect.Value) map[string]reflect.Value {
-	m := make(map[string]reflect.Value)
-	structValue(m, value)
-	return m
-}
-
 var (
 	typeValuer = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
 )
 
-func structValue(m map[string]reflect.Value, value reflect.Value) {
-	if value.Type().Implements(typeValuer) {
+// tagOptions are the comma separated options following the column name in a
+// db struct tag, e.g. `db:"id,pk,autoincrement"`
+type tagOptions string
+
+func parseTag(tag string) (string, tagOptions) {
+	name, options, _ := strings.Cut(tag, ",")
+	return name, tagOptions(options)
+}
+
+// Contains reports whether the option is set
+func (o tagOptions) Contains(option string) bool {
+	s := string(o)
+	for s != "" {
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the value of an option given as option=value. It reports
+// whether the option is set, with or without a value.
+func (o tagOptions) Lookup(option string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var name string
+		name, s, _ = strings.Cut(s, ",")
+		if key, value, _ := strings.Cut(name, "="); key == option {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// fieldInfo describes a struct field that maps to a column
+type fieldInfo struct {
+	name      string
+	index     []int
+	options   tagOptions
+	leaf      bool
+	prefixed  bool  // the field belongs to a struct tagged with prefix
+	optionals []int // positions in structInfo.optionals of the pointers on its path
+}
+
+var typeTime = reflect.TypeOf(time.Time{})
+
+// structInfo is the column metadata of a struct type
+type structInfo struct {
+	fields    []fieldInfo
+	byName    map[string]int
+	keys      []string
+	optionals [][]int // paths of pointers to structs tagged with prefix
+	relations map[string]*relation
+}
+
+var structInfoCache sync.Map // map[reflect.Type]*structInfo
+
+// getStructInfo returns the column metadata of the struct type t, which is
+// computed once per type and cached for subsequent calls
+func getStructInfo(t reflect.Type) *structInfo {
+	if info, ok := structInfoCache.Load(t); ok {
+		return info.(*structInfo)
+	}
+
+	c := fieldCollector{seen: make(map[string]bool)}
+	c.collect(t, nil, "", nil)
+
+	info := &structInfo{
+		fields:    c.fields,
+		byName:    make(map[string]int),
+		optionals: c.optionals,
+		relations: parseRelations(t),
+	}
+	for i, field := range info.fields {
+		info.byName[field.name] = i
+		if field.prefixed {
+			continue
+		}
+		if field.options.Contains("pk") || field.options.Contains("autoincrement") {
+			info.keys = append(info.keys, field.name)
+		}
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(t, info)
+	return actual.(*structInfo)
+}
+
+// fieldValue returns the value of the field of the struct v mapped to column
+func (info *structInfo) fieldValue(v reflect.Value, column string) (reflect.Value, bool) {
+	i, ok := info.byName[column]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return fieldByIndex(v, info.fields[i].index)
+}
+
+// fieldCollector collects the fields of a struct type that map to a column in
+// declaration order, including the fields of nested structs. Leaf fields hold
+// a single column value, as opposed to nested structs whose fields are listed
+// as well. The columns of a nested struct tagged with a prefix, as in
+// `db:"artist,prefix=artist__"`, are prefixed so they do not clash with the
+// columns of the outer struct. A prefix option without a value defaults to the
+// column name followed by a dot, as in "artist.id".
+type fieldCollector struct {
+	fields    []fieldInfo
+	optionals [][]int
+	seen      map[string]bool
+}
+
+func (c *fieldCollector) collect(t reflect.Type, index []int, prefix string, optionals []int) {
+	if reflect.PointerTo(t).Implements(typeValuer) || t.Implements(typeValuer) {
 		return
 	}
-	switch value.Kind() {
-	case reflect.Ptr:
-		if value.IsNil() {
-			return
-		}
-		structValue(m, value.Elem())
-	case reflect.Struct:
-		t := value.Type()
-		for i := 0; i < t.NumField(); i++ {
-			field := t.Field(i)
-			if field.PkgPath != "" && !field.Anonymous {
-				// unexported
-				continue
-			}
-			tag := field.Tag.Get("db")
-			if tag == "-" {
-				// ignore
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		name, options := parseTag(field.Tag.Get("db"))
+		if _, ok := field.Tag.Lookup("qb"); name == "-" || ok {
+			continue
+		}
+		if name == "" {
+			name = camelCaseToSnakeCase(field.Name)
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		nested := ft.Kind() == reflect.Struct && ft != typeTime &&
+			!reflect.PointerTo(ft).Implements(typeValuer) && !reflect.PointerTo(ft).Implements(typeScanner)
+
+		if nested {
+			if p, ok := options.Lookup("prefix"); ok {
+				if p == "" {
+					p = name + "."
+				}
+				fieldOptionals := optionals
+				if field.Type.Kind() == reflect.Ptr {
+					fieldOptionals = append(optionals[:len(optionals):len(optionals)], len(c.optionals))
+					c.optionals = append(c.optionals, fieldIndex)
+				}
+				c.collect(ft, fieldIndex, prefix+p, fieldOptionals)
 				continue
 			}
-			if tag == "" {
-				// no tag, but we can record the field name
-				tag = camelCaseToSnakeCase(field.Name)
-			}
-			fieldValue := value.Field(i)
-			if _, ok := m[tag]; !ok {
-				m[tag] = fieldValue
+		}
+
+		name = prefix + name
+		if !c.seen[name] {
+			c.seen[name] = true
+			c.fields = append(c.fields, fieldInfo{
+				name:      name,
+				index:     fieldIndex,
+				options:   options,
+				leaf:      !nested && !field.Anonymous,
+				prefixed:  prefix != "",
+				optionals: optionals,
+			})
+		}
+
+		if ft.Kind() == reflect.Struct {
+			c.collect(ft, fieldIndex, prefix, optionals)
+		}
+	}
+}
+
+// fieldByIndex returns the nested field of v at index. It reports false if a
+// nil pointer is encountered along the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
 			}
-			structValue(m, fieldValue)
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// recordValues returns the column names and values of the leaf fields of the
+// struct v that should be written. Fields tagged readonly are never written,
+// fields tagged pk or autoincrement are skipped for updates and zero valued
+// fields tagged autoincrement or omitempty are skipped altogether.
+func recordValues(v reflect.Value, update bool) ([]string, []interface{}) {
+	var columns []string
+	var values []interface{}
+	for _, field := range getStructInfo(v.Type()).fields {
+		if !field.leaf || field.prefixed || field.options.Contains("readonly") {
+			continue
+		}
+		if update && (field.options.Contains("pk") || field.options.Contains("autoincrement")) {
+			continue
+		}
+		fv, ok := fieldByIndex(v, field.index)
+		if (field.options.Contains("autoincrement") || field.options.Contains("omitempty")) && (!ok || fv.IsZero()) {
+			continue
+		}
+		columns = append(columns, field.name)
+		if ok {
+			values = append(values, fv.Interface())
+		} else {
+			values = append(values, nil)
 		}
 	}
+	return columns, values
 }
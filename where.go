@@ -6,18 +6,46 @@ import (
 )
 
 type whereClause struct {
-	wheres []string
-	params []interface{}
+	wheres []Builder
 }
 
-func (w *whereClause) addWhere(condition string, params ...interface{}) {
-	w.wheres = append(w.wheres, condition)
-	w.params = append(w.params, params...)
+func (w *whereClause) addWhere(condition interface{}, params ...interface{}) {
+	w.wheres = append(w.wheres, toExpr(condition, params...))
 }
 
-func (w *whereClause) writeWhere(buf *bytes.Buffer) {
+func (w *whereClause) writeWhere(buf *bytes.Buffer) error {
 	if len(w.wheres) > 0 {
 		buf.WriteString(" WHERE ")
-		buf.WriteString(strings.Join(w.wheres, " AND "))
+		return annotate(writeJoined(buf, w.wheres, " AND "), "", "WHERE")
 	}
+	return nil
+}
+
+func (w *whereClause) whereParams() []interface{} {
+	return joinedParams(w.wheres)
+}
+
+// writeLimitedWhere renders the WHERE clause of an UPDATE or DELETE query. As
+// SQLite is rarely compiled with SQLITE_ENABLE_UPDATE_DELETE_LIMIT an ORDER BY
+// or LIMIT is emulated by selecting the matching rowids in a subquery.
+func (w *whereClause) writeLimitedWhere(buf *bytes.Buffer, table string, orderBys []string, limit string) error {
+	if len(orderBys) == 0 && limit == "" {
+		return w.writeWhere(buf)
+	}
+
+	buf.WriteString(" WHERE rowid IN (SELECT rowid FROM ")
+	buf.WriteString(table)
+	if err := w.writeWhere(buf); err != nil {
+		return err
+	}
+	if len(orderBys) != 0 {
+		buf.WriteString(" ORDER BY ")
+		buf.WriteString(strings.Join(orderBys, ", "))
+	}
+	if limit != "" {
+		buf.WriteString(" LIMIT ")
+		buf.WriteString(limit)
+	}
+	buf.WriteString(")")
+	return nil
 }
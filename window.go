@@ -0,0 +1,146 @@
+package qb
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// WindowDef represents the definition of a window used by window functions
+type WindowDef struct {
+	partitionBy []string
+	orderBys    []string
+	frame       string
+}
+
+// Window creates a new window definition
+func Window() *WindowDef {
+	return &WindowDef{}
+}
+
+// PartitionBy adds a PARTITION BY clause to the window definition
+func (w *WindowDef) PartitionBy(columns ...string) *WindowDef {
+	w.partitionBy = append(w.partitionBy, columns...)
+	return w
+}
+
+// OrderBy adds an ORDER BY clause to the window definition
+func (w *WindowDef) OrderBy(column string, direction string) *WindowDef {
+	w.orderBys = append(w.orderBys, column+" "+direction)
+	return w
+}
+
+// Frame sets the frame specification of the window definition, e.g.
+// "ROWS BETWEEN 1 PRECEDING AND CURRENT ROW"
+func (w *WindowDef) Frame(frame string) *WindowDef {
+	w.frame = frame
+	return w
+}
+
+// Build renders the window definition without the surrounding parentheses
+func (w *WindowDef) Build(buf *bytes.Buffer) error {
+	var parts []string
+	if len(w.partitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitionBy, ", "))
+	}
+	if len(w.orderBys) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(w.orderBys, ", "))
+	}
+	if w.frame != "" {
+		parts = append(parts, w.frame)
+	}
+	buf.WriteString(strings.Join(parts, " "))
+	return nil
+}
+
+// Params returns the parameters of the window definition
+func (w *WindowDef) Params() []interface{} {
+	return nil
+}
+
+// namedWindow is an entry of the WINDOW clause of a SELECT query
+type namedWindow struct {
+	name string
+	def  *WindowDef
+}
+
+// WindowFunc represents a window function call such as ROW_NUMBER()
+type WindowFunc struct {
+	call   string
+	params []interface{}
+}
+
+// WindowFn creates a window function from a raw function call, e.g.
+// WindowFn("SUM(amount)") for a running total
+func WindowFn(call string, params ...interface{}) *WindowFunc {
+	return &WindowFunc{call: call, params: params}
+}
+
+// RowNumber creates a ROW_NUMBER() window function
+func RowNumber() *WindowFunc {
+	return WindowFn("ROW_NUMBER()")
+}
+
+// Rank creates a RANK() window function
+func Rank() *WindowFunc {
+	return WindowFn("RANK()")
+}
+
+// DenseRank creates a DENSE_RANK() window function
+func DenseRank() *WindowFunc {
+	return WindowFn("DENSE_RANK()")
+}
+
+// Ntile creates a NTILE(n) window function
+func Ntile(n int) *WindowFunc {
+	return WindowFn(fmt.Sprintf("NTILE(%d)", n))
+}
+
+// Lag creates a LAG(column, offset) window function
+func Lag(column string, offset int) *WindowFunc {
+	return WindowFn(fmt.Sprintf("LAG(%s, %d)", column, offset))
+}
+
+// Lead creates a LEAD(column, offset) window function
+func Lead(column string, offset int) *WindowFunc {
+	return WindowFn(fmt.Sprintf("LEAD(%s, %d)", column, offset))
+}
+
+// FirstValue creates a FIRST_VALUE(column) window function
+func FirstValue(column string) *WindowFunc {
+	return WindowFn("FIRST_VALUE(" + column + ")")
+}
+
+// LastValue creates a LAST_VALUE(column) window function
+func LastValue(column string) *WindowFunc {
+	return WindowFn("LAST_VALUE(" + column + ")")
+}
+
+// Over applies the window function to a window, which is either the name of
+// a window declared using SelectQuery.Window or a *WindowDef
+func (f *WindowFunc) Over(window interface{}) Builder {
+	return &overExpr{fn: f, window: window}
+}
+
+type overExpr struct {
+	fn     *WindowFunc
+	window interface{}
+}
+
+func (o *overExpr) Build(buf *bytes.Buffer) error {
+	buf.WriteString(o.fn.call)
+	buf.WriteString(" OVER ")
+	switch w := o.window.(type) {
+	case string:
+		buf.WriteString(w)
+	case *WindowDef:
+		return writeSubquery(buf, w)
+	default:
+		return &BuildError{Clause: "OVER", Reason: fmt.Sprintf("unsupported window of type %T", o.window)}
+	}
+	return nil
+}
+
+func (o *overExpr) Params() []interface{} {
+	return o.fn.params
+}
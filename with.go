@@ -0,0 +1,93 @@
+package qb
+
+import (
+	"bytes"
+	"strings"
+)
+
+// commonTableExpression is a single named query of a WITH clause
+type commonTableExpression struct {
+	name      string
+	columns   []string
+	query     Builder
+	recursive bool
+}
+
+type withClause struct {
+	ctes []commonTableExpression
+}
+
+func (w *withClause) addWith(name string, columns []string, query Builder, recursive bool) {
+	w.ctes = append(w.ctes, commonTableExpression{name: name, columns: columns, query: query, recursive: recursive})
+}
+
+func (w *withClause) writeWith(buf *bytes.Buffer) error {
+	if len(w.ctes) == 0 {
+		return nil
+	}
+
+	buf.WriteString("WITH ")
+	for _, cte := range w.ctes {
+		if cte.recursive {
+			buf.WriteString("RECURSIVE ")
+			break
+		}
+	}
+
+	for i, cte := range w.ctes {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(cte.name)
+		if len(cte.columns) > 0 {
+			buf.WriteString("(")
+			buf.WriteString(strings.Join(cte.columns, ", "))
+			buf.WriteString(")")
+		}
+		buf.WriteString(" AS ")
+		query := cte.query
+		if m, ok := query.(*materializedExpr); ok {
+			buf.WriteString(m.hint)
+			query = m.query
+		}
+		if err := writeSubquery(buf, query); err != nil {
+			return annotate(err, "", "WITH")
+		}
+	}
+	buf.WriteString(" ")
+
+	return nil
+}
+
+func (w *withClause) withParams() []interface{} {
+	var p []interface{}
+	for _, cte := range w.ctes {
+		p = append(p, cte.query.Params()...)
+	}
+	return p
+}
+
+// Materialized hints SQLite to materialize the common table expression query
+// passed to With instead of inlining it
+func Materialized(query Builder) Builder {
+	return &materializedExpr{hint: "MATERIALIZED ", query: query}
+}
+
+// NotMaterialized hints SQLite to inline the common table expression query
+// passed to With instead of materializing it
+func NotMaterialized(query Builder) Builder {
+	return &materializedExpr{hint: "NOT MATERIALIZED ", query: query}
+}
+
+type materializedExpr struct {
+	hint  string
+	query Builder
+}
+
+func (m *materializedExpr) Build(buf *bytes.Buffer) error {
+	return m.query.Build(buf)
+}
+
+func (m *materializedExpr) Params() []interface{} {
+	return m.query.Params()
+}